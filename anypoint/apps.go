@@ -1,5 +1,11 @@
 package anypoint
 
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
 // App represents an application as returned by the ARMUI endpoint.
 type App struct {
 	ID     string `json:"id"`
@@ -28,6 +34,7 @@ type App struct {
 	Details struct {
 		Domain string `json:"domain,omitempty"`
 	} `json:"details"`
+	Labels map[string]string `json:"labels,omitempty"` // team, criticality, ... as set on the app
 }
 
 func (a App) GetType() string {
@@ -74,8 +81,15 @@ func FilterRTF(app App) bool {
 	return app.Target.Type == "MC" && app.Target.Subtype == "runtime-fabric"
 }
 
-func FilterCH1OrRTF(app App) bool {
-	return FilterCH1(app) || FilterRTF(app)
+// FilterCH2 returns true if an app is deployed to CloudHub 2.0.
+func FilterCH2(app App) bool {
+	return app.Target.Type == "MC" && app.Target.Subtype == "cloudhub-2"
+}
+
+// FilterMonitorable returns true if an app is deployed to a target that can be monitored:
+// CloudHub, CloudHub 2.0 or RTF.
+func FilterMonitorable(app App) bool {
+	return FilterCH1(app) || FilterCH2(app) || FilterRTF(app)
 }
 
 // FilterRunning returns true if an app is running.
@@ -84,16 +98,86 @@ func FilterRunning(app App) bool {
 	if FilterCH1(app) {
 		return app.LastReportedStatus == "STARTED"
 	}
-	// Check for RTF apps.
-	if FilterRTF(app) {
+	// Check for RTF and CloudHub 2.0 apps.
+	if FilterRTF(app) || FilterCH2(app) {
 		return app.Application.Status == "RUNNING"
 	}
 	// For other types, do not filter them out.
 	return true
 }
 
+// FilterStopped returns true if an app is deployed but stopped.
+func FilterStopped(app App) bool {
+	if FilterCH1(app) {
+		return app.LastReportedStatus == "STOPPED"
+	}
+	if FilterRTF(app) || FilterCH2(app) {
+		return app.Application.Status == "NOT_RUNNING"
+	}
+	return false
+}
+
+// FilterUndeployed returns true if an app is undeployed, i.e. its deployment was
+// removed from the target while the app is still registered.
+func FilterUndeployed(app App) bool {
+	return app.LastReportedStatus == "UNDEPLOYED"
+}
+
+// UnknownTargetTypes returns the sorted, distinct types of the apps deployed to
+// targets this CLI cannot monitor yet.
+func UnknownTargetTypes(apps []App) []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, app := range apps {
+		if FilterMonitorable(app) {
+			continue
+		}
+		t := app.GetType()
+		if t == "" {
+			t = "unknown"
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
 func FilterByName(name string) AppFilter {
 	return func(app App) bool {
 		return app.Artifact.Name == name
 	}
 }
+
+// FilterByNameContains returns a filter matching the apps whose name contains the given
+// substring, case-insensitively.
+func FilterByNameContains(substr string) AppFilter {
+	needle := strings.ToLower(substr)
+	return func(app App) bool {
+		return strings.Contains(strings.ToLower(app.Artifact.Name), needle)
+	}
+}
+
+// FilterByNameRegexp returns a filter matching the apps whose name matches re.
+func FilterByNameRegexp(re *regexp.Regexp) AppFilter {
+	return func(app App) bool {
+		return re.MatchString(app.Artifact.Name)
+	}
+}
+
+// FilterByID returns a filter matching the app with the given ARM ID.
+func FilterByID(id string) AppFilter {
+	return func(app App) bool {
+		return app.ID == id
+	}
+}
+
+// FilterByLabel returns a filter matching the apps whose label key is set to value.
+func FilterByLabel(key, value string) AppFilter {
+	return func(app App) bool {
+		v, ok := app.Labels[key]
+		return ok && v == value
+	}
+}
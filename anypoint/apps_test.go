@@ -0,0 +1,79 @@
+package anypoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// appsServer serves total apps named app-0, app-1, ... page by page, recording the
+// offset and limit of each request.
+func appsServer(t *testing.T, total int, paginate bool) (*httptest.Server, *[][2]int) {
+	t.Helper()
+	var requests [][2]int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/armui/api/v1/applications" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("x-anypnt-org-id") != "org" || r.Header.Get("x-anypnt-env-id") != "env" {
+			http.Error(w, "missing org or env header", http.StatusBadRequest)
+			return
+		}
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		requests = append(requests, [2]int{offset, limit})
+		resp := AppsResponse{}
+		for i := offset; i < total && (!paginate || i < offset+limit); i++ {
+			var app App
+			app.ID = fmt.Sprintf("app-%d", i)
+			resp.Data = append(resp.Data, app)
+		}
+		if paginate {
+			resp.Total = total
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestGetAppsPaginates(t *testing.T) {
+	srv, requests := appsServer(t, 7, true)
+	client := &Client{BaseURL: srv.URL, AccessToken: "token", AppsPageSize: 3}
+
+	apps, err := client.GetApps(context.Background(), "org", "env")
+	if err != nil {
+		t.Fatalf("GetApps: %v", err)
+	}
+	if len(apps) != 7 {
+		t.Fatalf("got %d apps, want 7", len(apps))
+	}
+	for i, app := range apps {
+		if want := fmt.Sprintf("app-%d", i); app.ID != want {
+			t.Errorf("apps[%d] = %s, want %s", i, app.ID, want)
+		}
+	}
+	want := [][2]int{{0, 3}, {3, 3}, {6, 3}}
+	if fmt.Sprint(*requests) != fmt.Sprint(want) {
+		t.Errorf("requested pages (offset, limit) %v, want %v", *requests, want)
+	}
+}
+
+func TestGetAppsWithoutTotal(t *testing.T) {
+	// An API that ignores the pagination returns every app at once, without a total.
+	srv, requests := appsServer(t, 5, false)
+	client := &Client{BaseURL: srv.URL, AccessToken: "token", AppsPageSize: 2}
+
+	apps, err := client.GetApps(context.Background(), "org", "env")
+	if err != nil {
+		t.Fatalf("GetApps: %v", err)
+	}
+	if len(apps) != 5 || len(*requests) != 1 {
+		t.Errorf("got %d apps in %d requests, want 5 in 1", len(apps), len(*requests))
+	}
+}
@@ -0,0 +1,40 @@
+package anypoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ValidateBaseURL checks that baseURL is an absolute http(s) URL, as expected for the
+// base URL of a private or dedicated control plane.
+func ValidateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: expected an absolute URL such as https://anypoint.example.com", baseURL)
+	}
+	return nil
+}
+
+// baseURLTransport sends the requests to the base URL of a private control plane in
+// place of the host they were built for, so that the generated API clients, which only
+// know the US, EU and GOV control planes, reach it too.
+type baseURLTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t baseURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.base.Scheme
+	req.URL.Host = t.base.Host
+	if prefix := strings.TrimSuffix(t.base.Path, "/"); prefix != "" && !strings.HasPrefix(req.URL.Path, prefix+"/") {
+		req.URL.Path = prefix + req.URL.Path
+	}
+	req.Host = ""
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,134 @@
+package anypoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mulesoft-anypoint/anypoint-client-go/org"
+)
+
+// BusinessGroup summarizes the metadata of a business group.
+type BusinessGroup struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	IsMaster         bool               `json:"isMaster"`
+	ParentID         string             `json:"parentId,omitempty"`
+	EnvironmentCount int                `json:"environmentCount"`
+	Entitlements     map[string]float64 `json:"entitlements,omitempty"`
+}
+
+// NewBusinessGroup builds the summary of a business group from its details.
+func NewBusinessGroup(detail *org.MasterBGDetail) BusinessGroup {
+	bg := BusinessGroup{
+		ID:               detail.GetId(),
+		Name:             detail.GetName(),
+		IsMaster:         detail.GetIsMaster(),
+		EnvironmentCount: len(detail.GetEnvironments()),
+		Entitlements:     entitlementsSummary(detail),
+	}
+	// The direct parent is the last of the parent organization IDs.
+	if parents := detail.GetParentOrganizationIds(); len(parents) > 0 {
+		bg.ParentID = parents[len(parents)-1]
+	}
+	return bg
+}
+
+// entitlementsSummary returns the assigned quantity of every entitlement of a business group.
+// Entitlements without an assigned quantity (e.g. feature toggles) are left out.
+func entitlementsSummary(detail *org.MasterBGDetail) map[string]float64 {
+	raw, err := json.Marshal(detail)
+	if err != nil {
+		return nil
+	}
+	var parsed struct {
+		Entitlements map[string]json.RawMessage `json:"entitlements"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil
+	}
+	summary := make(map[string]float64)
+	for name, value := range parsed.Entitlements {
+		var quantity struct {
+			Assigned *float64 `json:"assigned"`
+		}
+		if err := json.Unmarshal(value, &quantity); err == nil && quantity.Assigned != nil {
+			summary[name] = *quantity.Assigned
+		}
+	}
+	if len(summary) == 0 {
+		return nil
+	}
+	return summary
+}
+
+// GetBusinessGroups retrieves the given business group followed by its direct sub business groups.
+func (c *Client) GetBusinessGroups(ctx context.Context, orgID string) ([]BusinessGroup, error) {
+	root, err := c.GetBusinessGroup(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	groups := []BusinessGroup{NewBusinessGroup(root)}
+	for _, subID := range root.GetSubOrganizationIds() {
+		sub, err := c.GetBusinessGroup(ctx, subID)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, NewBusinessGroup(sub))
+	}
+	return groups, nil
+}
+
+// MaxBusinessGroupDepth is the number of levels of sub business groups GetBusinessGroupTree walks.
+const MaxBusinessGroupDepth = 10
+
+// BusinessGroupNode is a business group of a tree returned by GetBusinessGroupTree.
+type BusinessGroupNode struct {
+	BusinessGroup
+	Path         string            `json:"path"`  // names from the root, separated by "/"
+	Depth        int               `json:"depth"` // 0 for the root
+	Environments []org.Environment `json:"-"`
+}
+
+// GetBusinessGroupTree walks the given business group and its sub business groups
+// recursively, down to MaxBusinessGroupDepth levels, and returns them flattened in
+// depth-first order. A business group reached twice is only listed once.
+func (c *Client) GetBusinessGroupTree(ctx context.Context, rootOrgID string) ([]BusinessGroupNode, error) {
+	var nodes []BusinessGroupNode
+	visited := make(map[string]bool)
+	var walk func(orgID, parentPath string, depth int) error
+	walk = func(orgID, parentPath string, depth int) error {
+		if visited[orgID] {
+			return nil
+		}
+		visited[orgID] = true
+		detail, err := c.GetBusinessGroup(ctx, orgID)
+		if err != nil {
+			return fmt.Errorf("business group %s: %w", orgID, err)
+		}
+		node := BusinessGroupNode{
+			BusinessGroup: NewBusinessGroup(detail),
+			Path:          detail.GetName(),
+			Depth:         depth,
+			Environments:  detail.GetEnvironments(),
+		}
+		if parentPath != "" {
+			node.Path = parentPath + "/" + node.Path
+		}
+		nodes = append(nodes, node)
+		if depth >= MaxBusinessGroupDepth {
+			Logger.Warn("business group tree too deep, sub business groups skipped", "org", orgID, "depth", depth)
+			return nil
+		}
+		for _, subID := range detail.GetSubOrganizationIds() {
+			if err := walk(subID, node.Path, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(rootOrgID, "", 0); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
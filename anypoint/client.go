@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/mulesoft-anypoint/anypoint-client-go/authorization"
@@ -22,25 +26,127 @@ var anypointServers = []string{
 	"https://gov.anypoint.mulesoft.com",
 }
 
+// controlPlanes maps the control plane names to their index in anypointServers.
+var controlPlanes = map[string]int{
+	"us":  0,
+	"eu":  1,
+	"gov": 2,
+}
+
+// ServerIndex converts a control plane name (us, eu, gov) to its server index.
+// It returns -1 for an invalid control plane.
+func ServerIndex(controlPlane string) int {
+	if index, ok := controlPlanes[controlPlane]; ok {
+		return index
+	}
+	return -1
+}
+
+// ControlPlane converts a server index to its control plane name.
+func ControlPlane(serverIndex int) string {
+	for name, index := range controlPlanes {
+		if index == serverIndex {
+			return name
+		}
+	}
+	return "unknown"
+}
+
 // Client wraps the anypoint-client-go Client with additional context as needed.
 type Client struct {
 	ClientId     string
 	ClientSecret string
 	AccessToken  string
 	ServerIndex  int
+	BaseURL      string    // base URL of a private control plane, taking precedence over ServerIndex
 	ExpiresAt    time.Time // the time when the access token expires
 	InfluxDbId   int       // the InfluxDB ID for the organization
+	DatabaseName string    // the monitoring database named by the bootdata, if any
 	Org          string
 	Env          string
+
+	// MaxAttempts is the number of attempts of a request failing with 429, 5xx or a
+	// network error (DefaultMaxAttempts when zero).
+	MaxAttempts int
+	// RetryBaseDelay is the delay before the first retry, doubled on each attempt
+	// (DefaultRetryBaseDelay when zero).
+	RetryBaseDelay time.Duration
+	// Timezone is the IANA time zone the monitoring queries group their results in
+	// (LocalTimezone when empty).
+	Timezone string
+	// Since and Until bound the monitoring queries to an absolute time range, in place of
+	// their time window, when Since is set. Until is optional.
+	Since, Until time.Time
+	// Timeout is the timeout of each HTTP request (HTTPTimeout when zero). It must be
+	// set before the first request.
+	Timeout time.Duration
+	// AppsPageSize is the number of applications GetApps requests per page
+	// (DefaultAppsPageSize when zero).
+	AppsPageSize int
+
+	tokenMu    sync.RWMutex // guards AccessToken and ExpiresAt once the client is shared
+	httpOnce   sync.Once
+	httpc      *http.Client // HTTP client, built once and shared by the concurrent requests
+	orgAPIOnce sync.Once
+	orgAPI     *org.APIClient // generated org API client, built once and reused across calls
 }
 
-// NewClient authenticates and returns a new Client instance.
-func NewClient(ctx context.Context, serverIndex int, clientId, clientSecret string) (*Client, error) {
+// DefaultAppsPageSize is the page size of GetApps when Client.AppsPageSize is not set.
+const DefaultAppsPageSize = 100
+
+// HTTPTimeout is the timeout of the HTTP requests of the clients without a Timeout.
+var HTTPTimeout = 30 * time.Second
+
+// transport is shared by all the clients so that connections are pooled across the
+// concurrent monitoring requests, which all go to the same host.
+var transport = loggingTransport{func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = 100
+	t.MaxIdleConnsPerHost = 32
+	return t
+}()}
+
+// timeout returns the timeout of the HTTP requests of c.
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return HTTPTimeout
+}
+
+// httpClient returns the HTTP client of c, building it on first use.
+func (c *Client) httpClient() *http.Client {
+	c.httpOnce.Do(func() {
+		c.httpc = &http.Client{Timeout: c.timeout(), Transport: transport}
+		if c.BaseURL != "" {
+			if base, err := url.Parse(c.BaseURL); err == nil {
+				c.httpc.Transport = baseURLTransport{base: base, next: transport}
+			}
+		}
+	})
+	return c.httpc
+}
+
+// withTimeout bounds a call, retries included, by the request timeout. The returned
+// context is still cancelled with ctx, e.g. when the monitoring of the remaining apps
+// is abandoned.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.timeout())
+}
+
+// tokenRefreshMargin is how long before its expiration an access token is refreshed.
+const tokenRefreshMargin = time.Minute
+
+// loginConnectedApp authenticates the connected app and returns its access token
+// along with the time the token expires.
+func loginConnectedApp(ctx context.Context, httpClient *http.Client, clientId, clientSecret string) (string, time.Time, error) {
 	// This is pseudo-code; refer to anypoint-client-go documentation for actual usage.
 	creds := authorization.NewCredentialsWithDefaults()
 	creds.SetClientId(clientId)
 	creds.SetClientSecret(clientSecret)
-	apiClient := authorization.NewAPIClient(authorization.NewConfiguration())
+	cfg := authorization.NewConfiguration()
+	cfg.HTTPClient = httpClient
+	apiClient := authorization.NewAPIClient(cfg)
 	res, httpr, err := apiClient.DefaultApi.ApiV2Oauth2TokenPost(ctx).Credentials(*creds).Execute()
 	if err != nil {
 		var details string
@@ -50,75 +156,183 @@ func NewClient(ctx context.Context, serverIndex int, clientId, clientSecret stri
 		} else {
 			details = err.Error()
 		}
-		return nil, errors.New("error authenticating: " + details)
+		return "", time.Time{}, errors.New("error authenticating: " + RedactSecrets(details, clientSecret))
 	}
 	defer httpr.Body.Close()
 
 	// Calculate the token expiration time.
 	expiresIn := res.GetExpiresIn() // expiresIn is in seconds.
 	expirationTime := time.Now().Add(time.Duration(expiresIn) * time.Second)
+	return res.GetAccessToken(), expirationTime, nil
+}
+
+// NewClient authenticates and returns a new Client instance.
+// The persisted InfluxDB ID is reused when it was retrieved with the same connected app on
+// the same control plane, unless WithRefreshInfluxID is given.
+func NewClient(ctx context.Context, serverIndex int, clientId, clientSecret string, opts ...ClientOption) (*Client, error) {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	client := &Client{
 		ClientId:     clientId,
 		ClientSecret: clientSecret,
-		AccessToken:  res.GetAccessToken(),
 		ServerIndex:  serverIndex,
-		ExpiresAt:    expirationTime,
-		Org:          viper.GetString("org"),
-		Env:          viper.GetString("env"),
+		BaseURL:      viper.GetString(config.Key("baseUrl")),
+		Org:          viper.GetString(config.Key("org")),
+		Env:          viper.GetString(config.Key("env")),
 	}
-	// Retrieve the InfluxDB ID from bootdata.
-	_, err = client.GetInfluxDBID(ctx)
+	accessToken, expirationTime, err := loginConnectedApp(ctx, client.httpClient(), clientId, clientSecret)
 	if err != nil {
+		return nil, err
+	}
+	client.AccessToken = accessToken
+	client.ExpiresAt = expirationTime
+	// Retrieve the InfluxDB ID from bootdata, unless it is already known.
+	if id := persistedInfluxDBID(client); id != 0 && !options.refreshInfluxID {
+		client.InfluxDbId = id
+		client.DatabaseName = viper.GetString(config.Key("influxdbDatabase"))
+		Logger.Debug("reusing persisted InfluxDB ID", "influxdbId", id)
+	} else if _, err := client.GetInfluxDBID(ctx); err != nil {
 		return nil, errors.New("error retrieving InfluxDB ID: " + err.Error())
 	}
-	// Optionally, you might log or print the expiration for debugging:
-	// fmt.Printf("Access token will expire at: %s\n", expirationTime.Format(time.RFC1123))
+	Logger.Info("authenticated", "clientId", clientId, "expiresAt", expirationTime.Format(time.RFC3339))
 	// You store the client in a global context for later retrieval.
 	setGlobalClient(client)
 	return client, nil
 }
 
+// persistedInfluxDBID returns the persisted InfluxDB ID if it was retrieved for the
+// connected app and control plane of client, or 0.
+func persistedInfluxDBID(client *Client) int {
+	if viper.GetString(config.Key("clientId")) != client.ClientId || viper.GetInt(config.Key("serverIndex")) != client.ServerIndex {
+		return 0
+	}
+	return viper.GetInt(config.Key("influxdbId"))
+}
+
 // For simplicity, we store the client globally.
 // In a production app, you’d likely use proper dependency injection or context management.
-var globalClient *Client
+var (
+	globalMu     sync.RWMutex // guards globalClient and the persistence of the configuration
+	globalClient *Client
+)
 
+// loadGlobalClient returns the global client, or nil before it is initialized.
+func loadGlobalClient() *Client {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalClient
+}
+
+// setGlobalClient persists the configuration of client and makes it the global client.
+// It reads the token directly: the caller holds client.tokenMu, or has not shared client yet.
 func setGlobalClient(client *Client) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
 	// Persist configuration values using Viper.
-	// In production, consider more secure storage for sensitive values.
-	viper.Set("clientId", client.ClientId)
-	viper.Set("clientSecret", client.ClientSecret)
-	viper.Set("serverIndex", client.ServerIndex)
-	viper.Set("accessToken", client.AccessToken)
-	viper.Set("expiresAt", client.ExpiresAt.Format(time.RFC3339))
-	viper.Set("influxdbId", client.InfluxDbId)
-	viper.Set("org", client.Org)
-	viper.Set("env", client.Env)
+	// Sensitive values go to the OS keyring when available. A secret given through the
+	// environment, and the token obtained with it, are kept in memory only; a persisted
+	// token is cleared so that the next run logs in again rather than reuse it.
+	viper.Set(config.Key("clientId"), client.ClientId)
+	secretFromEnv := config.FromEnv(config.Key("clientSecret"))
+	if !secretFromEnv {
+		if err := config.SetSecret(config.Key("clientSecret"), client.ClientSecret); err != nil {
+			Logger.Warn("unable to store client secret", "err", err)
+		}
+	}
+	viper.Set(config.Key("serverIndex"), client.ServerIndex)
+	viper.Set(config.Key("baseUrl"), client.BaseURL)
+	token := client.AccessToken
+	if secretFromEnv {
+		token = ""
+	}
+	if err := config.SetSecret(config.Key("accessToken"), token); err != nil {
+		Logger.Warn("unable to store access token", "err", err)
+	}
+	viper.Set(config.Key("expiresAt"), client.ExpiresAt.Format(time.RFC3339))
+	viper.Set(config.Key("influxdbId"), client.InfluxDbId)
+	viper.Set(config.Key("influxdbDatabase"), client.DatabaseName)
+	viper.Set(config.Key("org"), client.Org)
+	viper.Set(config.Key("env"), client.Env)
 
 	//Save conf
 	if err := config.SaveConfig(); err != nil {
-		fmt.Printf("Warning: Unable to persist configuration: %v\n", err)
+		Logger.Warn("unable to persist configuration", "err", err)
 	}
 	globalClient = client
 }
 
+// clientOptions holds the options of NewClient and GetClientFromContext.
+type clientOptions struct {
+	skipTokenExpiration bool
+	refreshInfluxID     bool
+}
+
+// ClientOption customizes how NewClient and GetClientFromContext load the client.
+type ClientOption func(*clientOptions)
+
+// WithSkipTokenExpiration returns the client even if its token is expired, without
+// refreshing it. It is meant for commands that only report the client state.
+func WithSkipTokenExpiration() ClientOption {
+	return func(o *clientOptions) {
+		o.skipTokenExpiration = true
+	}
+}
+
+// WithRefreshInfluxID makes NewClient retrieve the InfluxDB ID from bootdata even when a
+// persisted one could be reused, e.g. after the monitoring datasource changed.
+func WithRefreshInfluxID() ClientOption {
+	return func(o *clientOptions) {
+		o.refreshInfluxID = true
+	}
+}
+
 // GetClientFromContext retrieves the global client.
 // If the global client is nil, it attempts to read persisted configuration from Viper
-// and recreate the client if the stored token is still valid.
-func GetClientFromContext() (*Client, error) {
+// and recreate the client, refreshing the stored token if it is expired.
+func GetClientFromContext(opts ...ClientOption) (*Client, error) {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	// If the global client is already initialized, return it.
-	if globalClient != nil {
-		return globalClient, nil
+	if client := loadGlobalClient(); client != nil {
+		if options.skipTokenExpiration {
+			return client, nil
+		}
+		if err := client.ensureValidToken(context.Background()); err != nil {
+			return nil, err
+		}
+		return client, nil
 	}
 
-	// Attempt to read persisted configuration using Viper.
-	clientId := viper.GetString("clientId")
-	clientSecret := viper.GetString("clientSecret")
-	serverIndex := viper.GetInt("serverIndex")
-	accessToken := viper.GetString("accessToken")
-	expiresAtStr := viper.GetString("expiresAt")
-	influxDbId := viper.GetInt("influxdbId")
-	org := viper.GetString("org")
-	env := viper.GetString("env")
+	// Attempt to read the configuration using Viper. The MULETRACKER_* environment
+	// variables take precedence over the configuration file.
+	clientId := viper.GetString(config.Key("clientId"))
+	clientSecret := config.GetSecret(config.Key("clientSecret"))
+	serverIndex := viper.GetInt(config.Key("serverIndex"))
+	baseURL := viper.GetString(config.Key("baseUrl"))
+	accessToken := config.GetSecret(config.Key("accessToken"))
+	expiresAtStr := viper.GetString(config.Key("expiresAt"))
+	influxDbId := viper.GetInt(config.Key("influxdbId"))
+	databaseName := viper.GetString(config.Key("influxdbDatabase"))
+	org := viper.GetString(config.Key("org"))
+	env := viper.GetString(config.Key("env"))
+	if controlPlane := viper.GetString(config.Key("controlplane")); controlPlane != "" {
+		serverIndex = ServerIndex(controlPlane)
+		if serverIndex == -1 {
+			return nil, fmt.Errorf("invalid control plane %q. Valid values are 'eu', 'us', or 'gov'", controlPlane)
+		}
+	}
+
+	// Credentials provided without a session, e.g. through the environment in a CI job:
+	// log in as the 'connect' command would.
+	if !options.skipTokenExpiration && clientId != "" && clientSecret != "" && (accessToken == "" || expiresAtStr == "" || influxDbId == 0) {
+		return NewClient(context.Background(), serverIndex, clientId, clientSecret)
+	}
 
 	// Check that all required configuration values are available.
 	if clientId == "" || clientSecret == "" || accessToken == "" || expiresAtStr == "" || influxDbId == 0 {
@@ -131,32 +345,78 @@ func GetClientFromContext() (*Client, error) {
 		return nil, fmt.Errorf("invalid expiration time in configuration: %w", err)
 	}
 
-	// Check if the token is still valid.
-	if time.Now().After(expiresAt) {
-		return nil, errors.New("access token expired. Please run 'connect' command")
-	}
-
 	// Recreate and store the client from configuration.
-	globalClient = &Client{
+	client := &Client{
 		ClientId:     clientId,
 		ClientSecret: clientSecret,
 		AccessToken:  accessToken,
 		ServerIndex:  serverIndex,
+		BaseURL:      baseURL,
 		ExpiresAt:    expiresAt,
 		InfluxDbId:   influxDbId,
+		DatabaseName: databaseName,
 		Org:          org,
 		Env:          env,
 	}
-	return globalClient, nil
+
+	// Refresh the token if it is expired or about to expire.
+	if !options.skipTokenExpiration {
+		if err := client.ensureValidToken(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	globalMu.Lock()
+	globalClient = client
+	globalMu.Unlock()
+	return client, nil
+}
+
+// IsTokenExpired reports whether the access token is expired.
+func (c *Client) IsTokenExpired() bool {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return !time.Now().Before(c.ExpiresAt)
+}
+
+// token returns the access token, which may be refreshed concurrently.
+func (c *Client) token() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.AccessToken
+}
+
+// ensureValidToken re-authenticates the connected app when the access token is expired
+// or about to expire, then persists the new token. It only fails when re-authentication does.
+func (c *Client) ensureValidToken(ctx context.Context) error {
+	// Hold the lock while refreshing, so that concurrent callers wait for the new token
+	// rather than log in again.
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if time.Now().Add(tokenRefreshMargin).Before(c.ExpiresAt) {
+		return nil
+	}
+	accessToken, expiresAt, err := loginConnectedApp(ctx, c.httpClient(), c.ClientId, c.ClientSecret)
+	if err != nil {
+		return fmt.Errorf("access token expired and could not be refreshed (%v). Please run 'connect' command", err)
+	}
+	c.AccessToken = accessToken
+	c.ExpiresAt = expiresAt
+	Logger.Info("access token refreshed", "expiresAt", expiresAt.Format(time.RFC3339))
+	setGlobalClient(c)
+	return nil
 }
 
 func (c *Client) SetOrg(org string) {
 	c.Org = org
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
 	setGlobalClient(c)
 }
 
 func (c *Client) SetEnv(env string) {
 	c.Env = env
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
 	setGlobalClient(c)
 }
 
@@ -170,30 +430,48 @@ func (c *Client) IsEnvEmpty() bool {
 
 // get the base URL for the Anypoint Platform API.
 func (c *Client) getServerHost() (string, error) {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/"), nil
+	}
 	if c.ServerIndex < 0 || c.ServerIndex >= len(anypointServers) {
 		return "", errors.New("invalid server index")
 	}
 	return anypointServers[c.ServerIndex], nil
 }
 
+// orgClient returns the generated org API client, building it on first use.
+func (c *Client) orgClient() *org.APIClient {
+	c.orgAPIOnce.Do(func() {
+		cfg := org.NewConfiguration()
+		cfg.HTTPClient = c.httpClient()
+		c.orgAPI = org.NewAPIClient(cfg)
+	})
+	return c.orgAPI
+}
+
 // GetBusinessGroups retrieves the business groups.
 func (c *Client) GetBusinessGroup(ctx context.Context, orgId string) (*org.MasterBGDetail, error) {
-	orgCtx := context.WithValue(context.WithValue(ctx, org.ContextAccessToken, c.AccessToken), org.ContextServerIndex, c.ServerIndex)
-	orgClient := org.NewAPIClient(org.NewConfiguration())
-	org, httpr, err := orgClient.DefaultApi.OrganizationsOrgIdGet(orgCtx, orgId).Execute()
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	orgCtx := context.WithValue(context.WithValue(ctx, org.ContextAccessToken, c.token()), org.ContextServerIndex, c.ServerIndex)
+	var detail org.MasterBGDetail
+	httpr, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		var (
+			resp *http.Response
+			err  error
+		)
+		detail, resp, err = c.orgClient().DefaultApi.OrganizationsOrgIdGet(orgCtx, orgId).Execute()
+		return resp, err
+	})
 	if err != nil {
-		var details string
 		if httpr != nil && httpr.StatusCode >= 400 {
 			defer httpr.Body.Close()
-			b, _ := io.ReadAll(httpr.Body)
-			details = string(b)
-		} else {
-			details = err.Error()
+			return nil, fmt.Errorf("error retrieving business groups: %w", c.newAPIError(httpr))
 		}
-		return nil, errors.New("error retrieving business groups: " + details)
+		return nil, errors.New("error retrieving business groups: " + c.sanitize(err.Error()))
 	}
 	defer httpr.Body.Close()
-	return &org, nil
+	return &detail, nil
 }
 
 // GetEnvironments retrieves environments for a given business group ID.
@@ -205,15 +483,45 @@ func (c *Client) GetEnvironments(ctx context.Context, bgId string) ([]org.Enviro
 	return org.GetEnvironments(), nil
 }
 
-// GetApps retrieves all applications for a given org and env.
+// GetApps retrieves all applications for a given org and env, requesting them page by
+// page until the total reported by the API is reached.
 func (c *Client) GetApps(ctx context.Context, orgID, envID string, filters ...AppFilter) ([]App, error) {
+	pageSize := c.AppsPageSize
+	if pageSize <= 0 {
+		pageSize = DefaultAppsPageSize
+	}
+	var apps []App
+	for {
+		page, err := c.getAppsPage(ctx, orgID, envID, len(apps), pageSize)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, page.Data...)
+		// Without a total, the API is not paginating: the page holds every app.
+		if len(page.Data) == 0 || page.Total <= 0 || len(apps) >= page.Total {
+			break
+		}
+	}
+
+	if len(filters) > 0 {
+		apps = FilterApps(apps, filters...)
+	}
+	return apps, nil
+}
+
+// getAppsPage retrieves the page of at most limit applications starting at offset.
+func (c *Client) getAppsPage(ctx context.Context, orgID, envID string, offset, limit int) (*AppsResponse, error) {
 	host, err := c.getServerHost()
 	if err != nil {
 		return nil, err
 	}
 
-	url := host + "/armui/api/v1/applications"
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	q := url.Values{}
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", host+"/armui/api/v1/applications?"+q.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -221,52 +529,60 @@ func (c *Client) GetApps(ctx context.Context, orgID, envID string, filters ...Ap
 	// Set required headers.
 	req.Header.Set("x-anypnt-org-id", orgID)
 	req.Header.Set("x-anypnt-env-id", envID)
-	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+c.token())
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		return c.httpClient().Do(req)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("non-OK status %d: %s", resp.StatusCode, string(body))
+		return nil, c.newAPIError(resp)
 	}
 
 	var appsResp AppsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&appsResp); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
-
-	apps := appsResp.Data
-	if len(filters) > 0 {
-		apps = FilterApps(apps, filters...)
-	}
-	return apps, nil
+	return &appsResp, nil
 }
 
 // GetLastCalledTime fetches the last time the given app was called.
 // It uses a query that calculates the 75th percentile of the avg_request_count
 // over the specified time window. It returns the timestamp of the latest data point.
 // The timeWindow parameter is a string (e.g. "15m", "24h", "3d") to define the lookback period.
+// It is ignored when the client has an absolute time range (see Since).
 func (c *Client) GetLastCalledTime(ctx context.Context, orgID, envID string, app App, timeWindow string) (time.Time, error) {
-	templateCH1 := `SELECT percentile("avg_request_count", 75) FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND time >= now() - %s GROUP BY time(1m), "app_id" fill(none) tz('Europe/Paris')`
-	templateRTF := `SELECT percentile("avg_request_count", 75) FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "cluster_id" = '%s' AND "app_id" = '%s' AND time >= now() - %s GROUP BY time(1m), "app_id" fill(none) tz('Europe/Paris')`
+	templateCH1 := `SELECT percentile("avg_request_count", 75) FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND %s GROUP BY time(1m), "app_id" fill(none) tz('%s')`
+	templateRTF := `SELECT percentile("avg_request_count", 75) FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "cluster_id" = '%s' AND "app_id" = '%s' AND %s GROUP BY time(1m), "app_id" fill(none) tz('%s')`
+	templateCH2 := `SELECT percentile("avg_request_count", 75) FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND %s GROUP BY time(1m), "app_id" fill(none) tz('%s')`
 	var query string
+	timeCond, err := c.timeCondition(timeWindow)
+	if err != nil {
+		return time.Time{}, err
+	}
 
 	if FilterCH1(app) {
 		query = fmt.Sprintf(
 			templateCH1,
-			orgID, envID, app.Details.Domain, timeWindow,
+			escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Details.Domain), timeCond, c.QueryTimezone(),
 		)
 	} else if FilterRTF(app) {
 		query = fmt.Sprintf(
 			templateRTF,
-			orgID, envID, app.Target.ID, app.Artifact.Name, timeWindow,
+			escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Target.ID), escapeInfluxString(app.Artifact.Name), timeCond, c.QueryTimezone(),
+		)
+	} else if FilterCH2(app) {
+		// CloudHub 2.0 apps are tagged with their name, without a cluster.
+		query = fmt.Sprintf(
+			templateCH2,
+			escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Artifact.Name), timeCond, c.QueryTimezone(),
 		)
 	} else {
-		fmt.Printf("Unsupported app target: %v\n", app)
+		Logger.Debug("unsupported app target", "app", app.ID, "target", app.Target.Type)
 		return time.Time{}, fmt.Errorf("unsupported app type: %s", app.Target.Type)
 	}
 
@@ -276,6 +592,7 @@ func (c *Client) GetLastCalledTime(ctx context.Context, orgID, envID string, app
 		AppID:      app.ID,
 		Query:      query,
 		InfluxDBId: c.InfluxDbId,
+		Database:   c.database(),
 	}
 
 	resp, err := c.queryInfluxDB(ctx, params)
@@ -299,26 +616,63 @@ func (c *Client) GetLastCalledTime(ctx context.Context, orgID, envID string, app
 	return time.Time{}, nil
 }
 
+// DataPoint is a timestamped value of a time series.
+type DataPoint struct {
+	Time  time.Time
+	Value float64
+}
+
 // GetRequestCount fetches the total number of requests for the given app
 // over the specified time window.
 // The timeWindow parameter is a string (e.g. "24h", "3d") to define the lookback period.
 func (c *Client) GetRequestCount(ctx context.Context, orgID, envID string, app App, timeWindow string) (int, error) {
-	templateCH1 := `SELECT sum("avg_request_count") FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND time >= now() - %s GROUP BY time(1m), "app_id" fill(none) tz('Europe/Paris')`
-	templateRTF := `SELECT sum("avg_request_count") FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "cluster_id" = '%s' AND "app_id" = '%s' AND time >= now() - %s GROUP BY time(1m), "app_id" fill(none) tz('Europe/Paris')`
+	series, err := c.GetRequestSeries(ctx, orgID, envID, app, timeWindow)
+	if err != nil {
+		return 0, fmt.Errorf("error querying request count: %w", err)
+	}
+	return TotalRequests(series), nil
+}
+
+// TotalRequests returns the total number of requests of a series returned by GetRequestSeries.
+func TotalRequests(series []DataPoint) int {
+	total := 0
+	for _, p := range series {
+		total += int(p.Value)
+	}
+	return total
+}
+
+// GetRequestSeries fetches the number of requests per minute for the given app over
+// the specified time window, or the client's absolute time range when set (see Since),
+// in chronological order. Minutes without requests are omitted.
+func (c *Client) GetRequestSeries(ctx context.Context, orgID, envID string, app App, timeWindow string) ([]DataPoint, error) {
+	templateCH1 := `SELECT sum("avg_request_count") FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND %s GROUP BY time(1m), "app_id" fill(none) tz('%s')`
+	templateRTF := `SELECT sum("avg_request_count") FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "cluster_id" = '%s' AND "app_id" = '%s' AND %s GROUP BY time(1m), "app_id" fill(none) tz('%s')`
+	templateCH2 := `SELECT sum("avg_request_count") FROM "app_inbound_metric" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND %s GROUP BY time(1m), "app_id" fill(none) tz('%s')`
 	var query string
+	timeCond, err := c.timeCondition(timeWindow)
+	if err != nil {
+		return nil, err
+	}
 
 	if FilterCH1(app) {
 		query = fmt.Sprintf(
 			templateCH1,
-			orgID, envID, app.Details.Domain, timeWindow,
+			escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Details.Domain), timeCond, c.QueryTimezone(),
 		)
 	} else if FilterRTF(app) {
 		query = fmt.Sprintf(
 			templateRTF,
-			orgID, envID, app.Target.ID, app.Artifact.Name, timeWindow,
+			escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Target.ID), escapeInfluxString(app.Artifact.Name), timeCond, c.QueryTimezone(),
+		)
+	} else if FilterCH2(app) {
+		// CloudHub 2.0 apps are tagged with their name, without a cluster.
+		query = fmt.Sprintf(
+			templateCH2,
+			escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Artifact.Name), timeCond, c.QueryTimezone(),
 		)
 	} else {
-		return 0, fmt.Errorf("unsupported app type: %s", app.Target.Type)
+		return nil, fmt.Errorf("unsupported app type: %s", app.Target.Type)
 	}
 
 	params := QueryParams{
@@ -327,23 +681,28 @@ func (c *Client) GetRequestCount(ctx context.Context, orgID, envID string, app A
 		AppID:      app.ID,
 		Query:      query,
 		InfluxDBId: c.InfluxDbId,
+		Database:   c.database(),
 	}
 
 	resp, err := c.queryInfluxDB(ctx, params)
 	if err != nil {
-		return 0, fmt.Errorf("error querying request count: %w", err)
+		return nil, fmt.Errorf("error querying request series: %w", err)
 	}
 
-	total := 0
+	var points []DataPoint
 	if len(resp.Results) > 0 && len(resp.Results[0].Series) > 0 {
 		series := resp.Results[0].Series[0]
 		for _, entry := range series.Values {
-			if countVal, ok := entry[1].(float64); ok {
-				total += int(countVal)
+			// The first column is "time" (epoch in ms), the second the request count.
+			ts, ok1 := entry[0].(float64)
+			countVal, ok2 := entry[1].(float64)
+			if ok1 && ok2 {
+				points = append(points, DataPoint{
+					Time:  time.Unix(0, int64(ts)*int64(time.Millisecond)),
+					Value: countVal,
+				})
 			}
 		}
-		return total, nil
 	}
-
-	return 0, nil
+	return points, nil
 }
@@ -0,0 +1,122 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+// initTestConfig keeps the configuration and the secrets the client persists in a
+// temporary directory and an in-memory keyring.
+func initTestConfig(t *testing.T) {
+	t.Helper()
+	keyring.MockInit()
+	viper.Reset()
+	t.Cleanup(func() {
+		viper.Reset()
+		globalMu.Lock()
+		globalClient = nil
+		globalMu.Unlock()
+	})
+	if err := config.InitConfig(filepath.Join(t.TempDir(), "config.yaml")); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+}
+
+// Run with -race: the apps are monitored concurrently while the access token is refreshed.
+func TestMonitorWhileTokenRefreshes(t *testing.T) {
+	initTestConfig(t)
+	var logins atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/oauth2/token") {
+			// Slow logins let the queries run while the token is being refreshed.
+			time.Sleep(10 * time.Millisecond)
+			n := logins.Add(1)
+			fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600,"token_type":"bearer"}`, n+1)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer token-1" && auth != "Bearer token-2" {
+			http.Error(w, `{"message":"unexpected token"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+	}))
+	defer srv.Close()
+
+	// The token expires within the refresh margin, so the first caller refreshes it.
+	client := &Client{
+		ClientId:     "client",
+		ClientSecret: "secret",
+		AccessToken:  "token-1",
+		ExpiresAt:    time.Now().Add(tokenRefreshMargin / 2),
+		BaseURL:      srv.URL,
+		InfluxDbId:   1,
+	}
+	var app App
+	app.Target.Type = "CLOUDHUB"
+	app.Details.Domain = "orders"
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 0 {
+				if err := client.ensureValidToken(ctx); err != nil {
+					t.Errorf("ensureValidToken: %v", err)
+				}
+				return
+			}
+			for j := 0; j < 10; j++ {
+				if _, err := client.GetRequestCount(ctx, "org", "env", app, "1h"); err != nil {
+					t.Errorf("GetRequestCount: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := logins.Load(); n != 1 {
+		t.Errorf("logged in %d times, want 1", n)
+	}
+	if got := client.token(); got != "token-2" {
+		t.Errorf("token = %q, want token-2", got)
+	}
+}
+
+func TestServerIndexControlPlaneRoundTrip(t *testing.T) {
+	for _, name := range []string{"us", "eu", "gov"} {
+		index := ServerIndex(name)
+		if index < 0 || index >= len(anypointServers) {
+			t.Errorf("ServerIndex(%q) = %d, want an index of anypointServers", name, index)
+			continue
+		}
+		if got := ControlPlane(index); got != name {
+			t.Errorf("ControlPlane(ServerIndex(%q)) = %q", name, got)
+		}
+	}
+	for index := range anypointServers {
+		if got := ServerIndex(ControlPlane(index)); got != index {
+			t.Errorf("ServerIndex(ControlPlane(%d)) = %d", index, got)
+		}
+	}
+	if got := ServerIndex("apac"); got != -1 {
+		t.Errorf("ServerIndex(apac) = %d, want -1", got)
+	}
+	if got := ControlPlane(len(anypointServers)); got != "unknown" {
+		t.Errorf("ControlPlane(%d) = %q, want unknown", len(anypointServers), got)
+	}
+}
@@ -0,0 +1,30 @@
+package anypoint
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// APIError is returned when the Anypoint Platform answers a request with a non-OK status,
+// so that callers can react to the status code, e.g. with errors.As.
+type APIError struct {
+	StatusCode int
+	URL        string
+	Body       string // response body, with secrets redacted
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("non-OK HTTP status %d: %s", e.StatusCode, e.Body)
+}
+
+// newAPIError builds the APIError of resp, reading and sanitizing its body. The caller
+// still closes the body.
+func (c *Client) newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	apiErr := &APIError{StatusCode: resp.StatusCode, Body: c.sanitize(string(body))}
+	if resp.Request != nil {
+		apiErr.URL = resp.Request.URL.String()
+	}
+	return apiErr
+}
@@ -0,0 +1,64 @@
+package anypoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Fabric represents a Runtime Fabric as returned by the fabrics endpoint.
+type Fabric struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// GetFabrics retrieves the Runtime Fabrics of the given org.
+func (c *Client) GetFabrics(ctx context.Context, orgID string) ([]Fabric, error) {
+	host, err := c.getServerHost()
+	if err != nil {
+		return nil, err
+	}
+
+	url := host + "/runtimefabric/api/organizations/" + orgID + "/fabrics"
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token())
+
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		return c.httpClient().Do(req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.newAPIError(resp)
+	}
+
+	var fabrics []Fabric
+	if err := json.NewDecoder(resp.Body).Decode(&fabrics); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	return fabrics, nil
+}
+
+// GetFabricNames returns a mapping of fabric ID to fabric name for the given org.
+func (c *Client) GetFabricNames(ctx context.Context, orgID string) (map[string]string, error) {
+	fabrics, err := c.GetFabrics(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(fabrics))
+	for _, f := range fabrics {
+		names[f.ID] = f.Name
+	}
+	return names, nil
+}
@@ -8,11 +8,18 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 // A path template for the monitoring API. The "%s" will be replaced with the InfluxDB ID.
 var influxDBPathTemplate = "/monitoring/api/visualizer/api/datasources/proxy/%s/query"
 
+// DefaultInfluxDatabase is the monitoring database queried when the bootdata doesn't name one.
+const DefaultInfluxDatabase = "dias"
+
+// InfluxDatabase, when set, overrides the database queried by all the clients.
+var InfluxDatabase string
+
 // QueryParams holds the parameters for querying the InfluxDB API.
 type QueryParams struct {
 	// For our purposes, these are used to build the query.
@@ -21,12 +28,33 @@ type QueryParams struct {
 	AppID      string
 	Query      string
 	InfluxDBId int
+	Database   string // DefaultInfluxDatabase when empty
+	Epoch      string // precision of the returned timestamps, "ms" when empty; "rfc3339" for RFC3339 strings
+}
+
+// influxStringEscaper escapes the characters that would end an InfluxQL string literal.
+var influxStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// escapeInfluxString escapes s for use in a single-quoted InfluxQL string literal, so that
+// identifiers coming from the platform cannot break or alter the queries.
+func escapeInfluxString(s string) string {
+	return influxStringEscaper.Replace(s)
+}
+
+// influxIdentifierEscaper escapes the characters that would end a double-quoted InfluxQL identifier.
+var influxIdentifierEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// escapeInfluxIdentifier escapes s for use in a double-quoted InfluxQL identifier, such as
+// the field, measurement and tag names of the custom metrics.
+func escapeInfluxIdentifier(s string) string {
+	return influxIdentifierEscaper.Replace(s)
 }
 
 // InfluxDBResponse represents the structure of the InfluxDB API response.
 type InfluxDBResponse struct {
 	Results []struct {
-		StatementID int `json:"statement_id"`
+		StatementID int    `json:"statement_id"`
+		Error       string `json:"error,omitempty"`
 		Series      []struct {
 			Name    string          `json:"name"`
 			Tags    interface{}     `json:"tags"`
@@ -41,12 +69,43 @@ type BootDataResponseMinimal struct {
 	Settings struct {
 		Datasources struct {
 			Influxdb struct {
-				ID int `json:"id"`
+				ID       int    `json:"id"`
+				Database string `json:"database"`
 			} `json:"influxdb"`
 		} `json:"datasources"`
 	} `json:"Settings"`
 }
 
+// Query runs a raw InfluxQL query against the monitoring datasource of the client. The epoch sets the precision of the returned
+// timestamps (see QueryParams). Errors reported by InfluxDB for a statement are returned.
+func (c *Client) Query(ctx context.Context, query, epoch string) (*InfluxDBResponse, error) {
+	params := QueryParams{
+		Query:      query,
+		InfluxDBId: c.InfluxDbId,
+		Database:   c.database(),
+		Epoch:      epoch,
+	}
+	resp, err := c.queryInfluxDB(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("statement %d: %s", result.StatementID, result.Error)
+		}
+	}
+	return resp, nil
+}
+
+// CheckDatasource runs a lightweight query to confirm that the monitoring datasource of
+// the client answers, so that a broken setup is reported before any monitoring query.
+func (c *Client) CheckDatasource(ctx context.Context) error {
+	if _, err := c.Query(ctx, "SHOW MEASUREMENTS LIMIT 1", ""); err != nil {
+		return fmt.Errorf("monitoring datasource %d (database %s) did not answer: %w", c.InfluxDbId, c.database(), err)
+	}
+	return nil
+}
+
 // queryInfluxDB performs the query against the monitoring endpoint and returns the parsed response.
 func (c *Client) queryInfluxDB(ctx context.Context, params QueryParams) (*InfluxDBResponse, error) {
 	// Get the host URL based on the client’s serverIndex.
@@ -69,36 +128,48 @@ func (c *Client) queryInfluxDB(ctx context.Context, params QueryParams) (*Influx
 
 	// Build URL query parameters.
 	q := url.Values{}
-	// Hardcoded database value from your example.
-	q.Add("db", `"dias"`)
+	database := params.Database
+	if database == "" {
+		database = DefaultInfluxDatabase
+	}
+	q.Add("db", strconv.Quote(database))
 	q.Add("q", params.Query)
-	q.Add("epoch", "ms")
+	switch params.Epoch {
+	case "":
+		q.Add("epoch", "ms")
+	case "rfc3339":
+		// InfluxDB returns RFC3339 timestamps when no epoch is given.
+	default:
+		q.Add("epoch", params.Epoch)
+	}
 
 	// Construct the full URL.
 	fullURL := fmt.Sprintf("%s?%s", baseURL, q.Encode())
 
 	// Create the HTTP request.
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Add the Bearer token from the client's accessToken.
-	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+c.token())
 
 	// Execute the HTTP request.
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		return c.httpClient().Do(req)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		// Read the body to provide additional error details.
-		body, _ := io.ReadAll(resp.Body)
-		// Debug log: print the raw response body (remove in production)
-		fmt.Printf("Raw response: %s\n", string(body))
-		return nil, fmt.Errorf("received non-OK HTTP status %d: %s", resp.StatusCode, string(body))
+		apiErr := c.newAPIError(resp)
+		Logger.Debug("raw response", "url", req.URL.Path, "status", resp.StatusCode, "body", apiErr.Body)
+		return nil, apiErr
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -114,7 +185,19 @@ func (c *Client) queryInfluxDB(ctx context.Context, params QueryParams) (*Influx
 	return &influxResp, nil
 }
 
-// GetInfluxDBID calls the bootdata endpoint and extracts the influxdb id.
+// database returns the monitoring database queried by c: InfluxDatabase when set,
+// otherwise the one named by the bootdata, otherwise DefaultInfluxDatabase.
+func (c *Client) database() string {
+	if InfluxDatabase != "" {
+		return InfluxDatabase
+	}
+	if c.DatabaseName != "" {
+		return c.DatabaseName
+	}
+	return DefaultInfluxDatabase
+}
+
+// GetInfluxDBID calls the bootdata endpoint and extracts the influxdb id and database.
 func (c *Client) GetInfluxDBID(ctx context.Context) (int, error) {
 	// Obtain the host using your helper (getMonitoringHost)
 	host, err := c.getServerHost()
@@ -124,16 +207,20 @@ func (c *Client) GetInfluxDBID(ctx context.Context) (int, error) {
 	bootDataURL := host + "/monitoring/api/visualizer/api/bootdata"
 
 	// Create the GET request.
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "GET", bootDataURL, nil)
 	if err != nil {
 		return 0, fmt.Errorf("error creating bootdata request: %w", err)
 	}
 
 	// Set the Authorization header.
-	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+c.token())
 
 	// Execute the request.
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		return c.httpClient().Do(req)
+	})
 	if err != nil {
 		return 0, fmt.Errorf("error executing bootdata request: %w", err)
 	}
@@ -141,10 +228,9 @@ func (c *Client) GetInfluxDBID(ctx context.Context) (int, error) {
 
 	// Check the response status.
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		// Debug log: print the raw response body (remove in production)
-		fmt.Printf("Raw response: %s\n", string(body))
-		return 0, fmt.Errorf("received non-OK HTTP status %d: %s", resp.StatusCode, string(body))
+		apiErr := c.newAPIError(resp)
+		Logger.Debug("raw response", "url", req.URL.Path, "status", resp.StatusCode, "body", apiErr.Body)
+		return 0, apiErr
 	}
 
 	// Read the response body.
@@ -160,6 +246,7 @@ func (c *Client) GetInfluxDBID(ctx context.Context) (int, error) {
 	}
 
 	c.InfluxDbId = bootData.Settings.Datasources.Influxdb.ID
+	c.DatabaseName = bootData.Settings.Datasources.Influxdb.Database
 	// Return the influxdb id.
 	return c.InfluxDbId, nil
 }
@@ -0,0 +1,40 @@
+package anypoint
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// logLevel is the minimum level of the logged messages. Warnings and errors are logged by default.
+var logLevel = func() *slog.LevelVar {
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	return level
+}()
+
+// Logger logs diagnostics to stderr, so that they never mix with the results printed on stdout.
+var Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+// ParseLogLevel parses a log level name: debug, info, warn or error.
+func ParseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(name))
+	return level, err
+}
+
+// SetLogLevel sets the minimum level of the logged messages, e.g. slog.LevelDebug to
+// include the raw API responses.
+func SetLogLevel(level slog.Level) {
+	logLevel.Set(level)
+}
+
+// loggingTransport logs the requests sent to the Anypoint Platform at debug level.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	Logger.Debug("request", "method", req.Method, "url", req.URL.Scheme+"://"+req.URL.Host+req.URL.Path)
+	return t.next.RoundTrip(req)
+}
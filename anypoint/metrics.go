@@ -0,0 +1,134 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CustomMetric describes an InfluxDB-backed metric defined by the user rather than in code.
+// The query aggregates Field of Measurement over Window for an app; the app is selected
+// with the org_id and env_id tags plus the tags mapped in CloudHub, CloudHub2 or RTF, depending on its target.
+type CustomMetric struct {
+	Name        string            `yaml:"name"`
+	Measurement string            `yaml:"measurement"`
+	Field       string            `yaml:"field"`
+	Aggregation string            `yaml:"aggregation"`
+	Window      string            `yaml:"window"`
+	CloudHub    map[string]string `yaml:"cloudhub"`  // tag name -> app attribute, for CloudHub apps
+	CloudHub2   map[string]string `yaml:"cloudhub2"` // tag name -> app attribute, for CloudHub 2.0 apps
+	RTF         map[string]string `yaml:"rtf"`       // tag name -> app attribute, for RTF apps
+}
+
+// MetricAggregations lists the supported aggregation functions.
+var MetricAggregations = []string{"mean", "median", "sum", "count", "min", "max", "last", "spread", "stddev"}
+
+// MetricAppAttributes lists the app attributes a tag can be mapped to.
+var MetricAppAttributes = []string{"id", "name", "domain", "target"}
+
+// appAttribute returns the value of the named app attribute.
+func appAttribute(app App, attribute string) string {
+	switch attribute {
+	case "id":
+		return app.ID
+	case "name":
+		return app.Artifact.Name
+	case "domain":
+		return app.Details.Domain
+	case "target":
+		return app.Target.ID
+	}
+	return ""
+}
+
+// Validate checks that the metric definition is complete and uses supported values.
+func (m CustomMetric) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("metric name is required")
+	}
+	if m.Measurement == "" || m.Field == "" || m.Window == "" {
+		return fmt.Errorf("metric %s: measurement, field and window are required", m.Name)
+	}
+	if err := ValidateWindow(m.Window); err != nil {
+		return fmt.Errorf("metric %s: %w", m.Name, err)
+	}
+	if !contains(MetricAggregations, m.Aggregation) {
+		return fmt.Errorf("metric %s: unsupported aggregation %q (valid: %s)", m.Name, m.Aggregation, strings.Join(MetricAggregations, ", "))
+	}
+	if len(m.CloudHub) == 0 && len(m.CloudHub2) == 0 && len(m.RTF) == 0 {
+		return fmt.Errorf("metric %s: at least one of the cloudhub, cloudhub2 or rtf tag mappings is required", m.Name)
+	}
+	for _, mapping := range []map[string]string{m.CloudHub, m.CloudHub2, m.RTF} {
+		for tag, attribute := range mapping {
+			if !contains(MetricAppAttributes, attribute) {
+				return fmt.Errorf("metric %s: tag %s maps to unsupported app attribute %q (valid: %s)", m.Name, tag, attribute, strings.Join(MetricAppAttributes, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCustomMetric computes a custom metric for the given app over the metric's window.
+// It returns false when no data point is available or when the metric has no tag
+// mapping for the app's target.
+func (c *Client) GetCustomMetric(ctx context.Context, orgID, envID string, app App, metric CustomMetric) (float64, bool, error) {
+	var mapping map[string]string
+	if FilterCH1(app) {
+		mapping = metric.CloudHub
+	} else if FilterCH2(app) {
+		mapping = metric.CloudHub2
+	} else if FilterRTF(app) {
+		mapping = metric.RTF
+	}
+	if len(mapping) == 0 {
+		return 0, false, nil
+	}
+
+	timeCond, err := c.timeCondition(metric.Window)
+	if err != nil {
+		return 0, false, fmt.Errorf("metric %s: %w", metric.Name, err)
+	}
+
+	conditions := []string{
+		fmt.Sprintf(`"org_id" = '%s'`, escapeInfluxString(orgID)),
+		fmt.Sprintf(`"env_id" = '%s'`, escapeInfluxString(envID)),
+	}
+	for tag, attribute := range mapping {
+		conditions = append(conditions, fmt.Sprintf(`"%s" = '%s'`, escapeInfluxIdentifier(tag), escapeInfluxString(appAttribute(app, attribute))))
+	}
+	query := fmt.Sprintf(`SELECT %s("%s") FROM "%s" WHERE %s AND %s fill(none)`,
+		metric.Aggregation, escapeInfluxIdentifier(metric.Field), escapeInfluxIdentifier(metric.Measurement), strings.Join(conditions, " AND "), timeCond)
+
+	params := QueryParams{
+		OrgID:      orgID,
+		EnvID:      envID,
+		AppID:      app.ID,
+		Query:      query,
+		InfluxDBId: c.InfluxDbId,
+		Database:   c.database(),
+	}
+
+	resp, err := c.queryInfluxDB(ctx, params)
+	if err != nil {
+		return 0, false, fmt.Errorf("error querying metric %s: %w", metric.Name, err)
+	}
+
+	if len(resp.Results) > 0 && len(resp.Results[0].Series) > 0 {
+		series := resp.Results[0].Series[0]
+		if len(series.Values) > 0 && len(series.Values[0]) > 1 {
+			if value, ok := series.Values[0][1].(float64); ok {
+				return value, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
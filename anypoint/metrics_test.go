@@ -0,0 +1,83 @@
+package anypoint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureQueries returns a client querying a fake InfluxDB that records the queries it receives.
+func captureQueries(t *testing.T) (*Client, *[]string) {
+	t.Helper()
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[{"statement_id":0}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return &Client{BaseURL: srv.URL, InfluxDbId: 1, AccessToken: "token"}, &queries
+}
+
+func TestGetCustomMetricEscapesIdentifiers(t *testing.T) {
+	client, queries := captureQueries(t)
+	var app App
+	app.Target.Type = "CLOUDHUB"
+	app.Details.Domain = "orders"
+	metric := CustomMetric{
+		Name:        "injected",
+		Measurement: `jvm" WHERE 1=1 --`,
+		Field:       `heap\"used`,
+		Aggregation: "mean",
+		Window:      "1h",
+		CloudHub:    map[string]string{`app_id" = '' OR "x`: "domain"},
+	}
+
+	if _, _, err := client.GetCustomMetric(context.Background(), "org", "env", app, metric); err != nil {
+		t.Fatalf("GetCustomMetric: %v", err)
+	}
+	if len(*queries) != 1 {
+		t.Fatalf("got %d queries, want 1", len(*queries))
+	}
+	want := `SELECT mean("heap\\\"used") FROM "jvm\" WHERE 1=1 --" WHERE "org_id" = 'org' AND "env_id" = 'env' AND "app_id\" = '' OR \"x" = 'orders' AND time >= now() - 1h fill(none)`
+	if got := (*queries)[0]; got != want {
+		t.Errorf("query =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGetCustomMetricRejectsInvalidWindow(t *testing.T) {
+	client, queries := captureQueries(t)
+	var app App
+	app.Target.Type = "CLOUDHUB"
+	metric := CustomMetric{
+		Name:        "injected",
+		Measurement: "jvm",
+		Field:       "heap_used",
+		Aggregation: "mean",
+		Window:      "1h OR 1=1",
+		CloudHub:    map[string]string{"app_id": "domain"},
+	}
+
+	_, _, err := client.GetCustomMetric(context.Background(), "org", "env", app, metric)
+	if err == nil || !strings.Contains(err.Error(), "invalid time window") {
+		t.Fatalf("GetCustomMetric error = %v, want an invalid time window", err)
+	}
+	if len(*queries) != 0 {
+		t.Errorf("sent %d queries with an invalid window, want none", len(*queries))
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	for _, window := range []string{"15m", "24h", "3d", "1w", "1d12h", "500ms", "10u", "10µ"} {
+		if err := ValidateWindow(window); err != nil {
+			t.Errorf("ValidateWindow(%q) = %v, want nil", window, err)
+		}
+	}
+	for _, window := range []string{"", "h", "1", "1y", "-1h", "1.5h", "1h ", "now()", "1h) OR (1=1"} {
+		if err := ValidateWindow(window); err == nil {
+			t.Errorf("ValidateWindow(%q) = nil, want an error", window)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package anypoint
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetCpuUsage fetches the mean CPU usage, in percent, of the given app over the
+// specified time window. It returns false when no data point is available.
+func (c *Client) GetCpuUsage(ctx context.Context, orgID, envID string, app App, timeWindow string) (float64, bool, error) {
+	value, ok, err := c.getResourceUsage(ctx, orgID, envID, app, "jvm.cpu.operatingsystem", "cpu", timeWindow)
+	if err != nil {
+		return 0, false, fmt.Errorf("error querying CPU usage: %w", err)
+	}
+	return value, ok, nil
+}
+
+// GetMemoryUsage fetches the mean heap memory used, in bytes, by the given app over the
+// specified time window. It returns false when no data point is available.
+func (c *Client) GetMemoryUsage(ctx context.Context, orgID, envID string, app App, timeWindow string) (float64, bool, error) {
+	value, ok, err := c.getResourceUsage(ctx, orgID, envID, app, "jvm.memory", "heap_used", timeWindow)
+	if err != nil {
+		return 0, false, fmt.Errorf("error querying memory usage: %w", err)
+	}
+	return value, ok, nil
+}
+
+// getResourceUsage returns the mean of field in measurement for the given app over the
+// time window, selecting the app the same way as the request queries.
+func (c *Client) getResourceUsage(ctx context.Context, orgID, envID string, app App, measurement, field, timeWindow string) (float64, bool, error) {
+	templateCH1 := `SELECT mean("%s") FROM "%s" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND %s fill(none)`
+	templateRTF := `SELECT mean("%s") FROM "%s" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "cluster_id" = '%s' AND "app_id" = '%s' AND %s fill(none)`
+	templateCH2 := `SELECT mean("%s") FROM "%s" WHERE "org_id" = '%s' AND "env_id" = '%s' AND "app_id" = '%s' AND %s fill(none)`
+	var query string
+	timeCond, err := c.timeCondition(timeWindow)
+	if err != nil {
+		return 0, false, err
+	}
+	field, measurement = escapeInfluxIdentifier(field), escapeInfluxIdentifier(measurement)
+
+	if FilterCH1(app) {
+		query = fmt.Sprintf(
+			templateCH1,
+			field, measurement, escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Details.Domain), timeCond,
+		)
+	} else if FilterRTF(app) {
+		query = fmt.Sprintf(
+			templateRTF,
+			field, measurement, escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Target.ID), escapeInfluxString(app.Artifact.Name), timeCond,
+		)
+	} else if FilterCH2(app) {
+		query = fmt.Sprintf(
+			templateCH2,
+			field, measurement, escapeInfluxString(orgID), escapeInfluxString(envID), escapeInfluxString(app.Artifact.Name), timeCond,
+		)
+	} else {
+		return 0, false, fmt.Errorf("unsupported app type: %s", app.Target.Type)
+	}
+
+	params := QueryParams{
+		OrgID:      orgID,
+		EnvID:      envID,
+		AppID:      app.ID,
+		Query:      query,
+		InfluxDBId: c.InfluxDbId,
+		Database:   c.database(),
+	}
+
+	resp, err := c.queryInfluxDB(ctx, params)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if len(resp.Results) > 0 && len(resp.Results[0].Series) > 0 {
+		series := resp.Results[0].Series[0]
+		if len(series.Values) > 0 && len(series.Values[0]) > 1 {
+			if value, ok := series.Values[0][1].(float64); ok {
+				return value, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,97 @@
+package anypoint
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts of a request when Client.MaxAttempts is not set.
+	DefaultMaxAttempts = 4
+	// DefaultRetryBaseDelay is the delay before the first retry when Client.RetryBaseDelay is not set.
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	// maxRetryDelay caps the delay between two attempts, including the one asked with Retry-After.
+	maxRetryDelay = 30 * time.Second
+)
+
+// doWithRetry calls fn until it succeeds, retrying on 429 and 5xx responses and on network
+// errors with exponential backoff and jitter. A Retry-After header on the response takes
+// precedence over the backoff. The last response or error is returned once the attempts
+// are exhausted.
+func (c *Client) doWithRetry(ctx context.Context, fn func() (*http.Response, error)) (*http.Response, error) {
+	maxAttempts := c.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := fn()
+		if attempt >= maxAttempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+		delay := c.retryDelay(attempt, resp)
+		if resp != nil {
+			Logger.Debug("retrying request", "attempt", attempt, "delay", delay, "status", resp.StatusCode)
+			// Drain the body so that the connection can be reused.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			Logger.Debug("retrying request", "attempt", attempt, "delay", delay, "err", err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether a request that returned resp and err is worth retrying.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp == nil {
+		// Network error.
+		return err != nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryDelay returns how long to wait after the given failed attempt.
+func (c *Client) retryDelay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return min(delay, maxRetryDelay)
+		}
+	}
+	base := c.RetryBaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	delay := base << (attempt - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	// Add up to 50% of jitter so that concurrent requests don't retry in lockstep, within the cap.
+	return min(delay+time.Duration(rand.Int63n(int64(delay)/2+1)), maxRetryDelay)
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
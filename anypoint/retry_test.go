@@ -0,0 +1,20 @@
+package anypoint
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	client := &Client{RetryBaseDelay: time.Second}
+	for attempt := 1; attempt <= 70; attempt++ {
+		if delay := client.retryDelay(attempt, nil); delay <= 0 || delay > maxRetryDelay {
+			t.Errorf("retryDelay(%d) = %s, want within (0, %s]", attempt, delay, maxRetryDelay)
+		}
+	}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"120"}}}
+	if delay := client.retryDelay(1, resp); delay != maxRetryDelay {
+		t.Errorf("retryDelay with Retry-After: 120 = %s, want %s", delay, maxRetryDelay)
+	}
+}
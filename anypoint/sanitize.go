@@ -0,0 +1,28 @@
+package anypoint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redactedSecret replaces secrets in sanitized messages.
+const redactedSecret = "********"
+
+// bearerPattern matches bearer credentials such as "Bearer eyJhbGciOi...".
+var bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s"',;]+`)
+
+// RedactSecrets masks the given secrets and any bearer credential found in s,
+// so that response bodies echoing them can be safely printed.
+func RedactSecrets(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedSecret)
+		}
+	}
+	return bearerPattern.ReplaceAllString(s, "${1}"+redactedSecret)
+}
+
+// sanitize masks the client's access token and secret, as well as bearer credentials, in s.
+func (c *Client) sanitize(s string) string {
+	return RedactSecrets(s, c.token(), c.ClientSecret)
+}
@@ -0,0 +1,62 @@
+package anypoint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		secrets []string
+		want    string
+	}{
+		{"secret", `{"error":"invalid client s3cr3t"}`, []string{"s3cr3t"}, `{"error":"invalid client ********"}`},
+		{"token in error body", `{"message":"token 0f8c2b1e is expired"}`, []string{"0f8c2b1e", "s3cr3t"}, `{"message":"token ******** is expired"}`},
+		{"bearer", `Authorization: Bearer eyJhbGciOi.abc, retry`, nil, `Authorization: Bearer ********, retry`},
+		{"bearer case", `{"auth":"bearer abc123"}`, nil, `{"auth":"bearer ********"}`},
+		{"empty secret", "nothing to hide", []string{""}, "nothing to hide"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactSecrets(tt.in, tt.secrets...); got != tt.want {
+				t.Errorf("RedactSecrets(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientSanitize(t *testing.T) {
+	client := &Client{AccessToken: "0f8c2b1e-token", ClientSecret: "app-secret"}
+	body := `{"message":"token 0f8c2b1e-token is not allowed","secret":"app-secret"}`
+	if got, want := client.sanitize(body), `{"message":"token ******** is not allowed","secret":"********"}`; got != want {
+		t.Errorf("sanitize(%q) = %q, want %q", body, got, want)
+	}
+}
+
+func TestAPIErrorRedactsToken(t *testing.T) {
+	const token = "0f8c2b1e-token"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"token ` + token + ` is not allowed","secret":"app-secret"}`))
+	}))
+	defer srv.Close()
+	client := &Client{BaseURL: srv.URL, AccessToken: token, ClientSecret: "app-secret"}
+
+	_, err := client.queryInfluxDB(context.Background(), QueryParams{Query: "SELECT 1", InfluxDBId: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("queryInfluxDB error = %v, want an APIError", err)
+	}
+	if strings.Contains(err.Error(), token) || strings.Contains(err.Error(), "app-secret") {
+		t.Errorf("error leaks a secret: %v", err)
+	}
+	if want := `{"message":"token ******** is not allowed","secret":"********"}`; apiErr.Body != want {
+		t.Errorf("Body = %q, want %q", apiErr.Body, want)
+	}
+}
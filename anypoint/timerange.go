@@ -0,0 +1,48 @@
+package anypoint
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// durationPattern matches an InfluxQL duration literal, such as 15m, 24h or 1d12h.
+var durationPattern = regexp.MustCompile(`^(\d+(ns|u|µ|ms|s|m|h|d|w))+$`)
+
+// ValidateWindow checks that window is an InfluxQL duration literal (e.g. 15m, 24h, 3d),
+// as it is inserted as-is in the queries.
+func ValidateWindow(window string) error {
+	if !durationPattern.MatchString(window) {
+		return fmt.Errorf("invalid time window %q: expected a duration such as 15m, 24h or 3d", window)
+	}
+	return nil
+}
+
+// ValidateTimeRange checks an absolute time range given with --since and --until.
+// until may be zero, in which case the range is open-ended.
+func ValidateTimeRange(since, until time.Time) error {
+	if since.IsZero() {
+		return fmt.Errorf("an absolute time range requires a start time")
+	}
+	if !until.IsZero() && !until.After(since) {
+		return fmt.Errorf("invalid time range: %s is not after %s", until.Format(time.RFC3339), since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// timeCondition returns the InfluxQL condition selecting the points of the queries: those
+// between c.Since and c.Until when an absolute range is set, otherwise those of the
+// last timeWindow. It fails when timeWindow is needed and is not a valid duration.
+func (c *Client) timeCondition(timeWindow string) (string, error) {
+	if c.Since.IsZero() {
+		if err := ValidateWindow(timeWindow); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("time >= now() - %s", timeWindow), nil
+	}
+	cond := fmt.Sprintf("time >= '%s'", c.Since.UTC().Format(time.RFC3339Nano))
+	if !c.Until.IsZero() {
+		cond += fmt.Sprintf(" AND time <= '%s'", c.Until.UTC().Format(time.RFC3339Nano))
+	}
+	return cond, nil
+}
@@ -0,0 +1,47 @@
+package anypoint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalTimezone returns the IANA name of the system time zone, taken from $TZ or the
+// /etc/localtime link. It falls back to UTC when the name cannot be determined.
+func LocalTimezone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if i := strings.LastIndex(target, "zoneinfo/"); i >= 0 {
+			name := target[i+len("zoneinfo/"):]
+			if _, err := time.LoadLocation(name); err == nil {
+				return name
+			}
+		}
+	}
+	return "UTC"
+}
+
+// ValidateTimezone checks that name is a time zone known to the system.
+func ValidateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return fmt.Errorf("invalid timezone %q: expected an IANA name such as Europe/Paris", name)
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return nil
+}
+
+// QueryTimezone returns the time zone the monitoring queries group their results in.
+func (c *Client) QueryTimezone() string {
+	if c.Timezone != "" {
+		return c.Timezone
+	}
+	return LocalTimezone()
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+// OrphanApp is an app deployed in a non-production environment that received no traffic.
+type OrphanApp struct {
+	AppID       string    `json:"appId"`
+	AppType     string    `json:"appType"`
+	EnvID       string    `json:"envId"`
+	EnvName     string    `json:"envName"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// findOrphansInEnv returns the running apps of an environment with a zero request count
+// over the given window, along with the apps whose request count could not be retrieved
+// and the apps deployed to targets that cannot be monitored, so that the caller reports
+// them once for all the environments.
+func findOrphansInEnv(ctx context.Context, client *anypoint.Client, orgID, envID, envName, window string) (orphans []OrphanApp, failed, unmonitorable []anypoint.App, err error) {
+	apps, err := client.GetApps(ctx, orgID, envID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	unmonitorable = anypoint.FilterApps(apps, func(app anypoint.App) bool { return !anypoint.FilterMonitorable(app) })
+	apps = anypoint.FilterApps(apps, anypoint.FilterRunning, anypoint.FilterMonitorable)
+
+	sem := make(chan struct{}, defaultConcurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	rateLimiter := newRateLimiter(defaultRate)
+	defer rateLimiter.Stop()
+
+	for _, app := range apps {
+		wg.Add(1)
+		go func(app anypoint.App) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}: // Acquire semaphore.
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }() // Release semaphore.
+			select {
+			case <-rateLimiter.C: // Wait for rate limiter tick.
+			case <-ctx.Done():
+				return
+			}
+			count, err := client.GetRequestCount(ctx, orgID, envID, app, window)
+			if err != nil {
+				anypoint.Logger.Error("error monitoring app", "app", app.Artifact.Name, "err", err)
+				mu.Lock()
+				failed = append(failed, app)
+				mu.Unlock()
+				return
+			}
+			if count > 0 {
+				return
+			}
+			mu.Lock()
+			orphans = append(orphans, OrphanApp{
+				AppID:       app.Artifact.Name,
+				AppType:     app.GetType(),
+				EnvID:       envID,
+				EnvName:     envName,
+				LastUpdated: time.UnixMilli(app.Artifact.LastUpdateTime),
+			})
+			mu.Unlock()
+		}(app)
+	}
+	wg.Wait()
+	return orphans, failed, unmonitorable, nil
+}
+
+// printOrphansTable prints the orphan apps using tabwriter for alignment.
+func printOrphansTable(orphans []OrphanApp) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "App ID\tType\tEnvironment\tLast Updated")
+	fmt.Fprintln(w, "------\t----\t-----------\t------------")
+	for _, o := range orphans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", o.AppID, o.AppType, o.EnvName, o.LastUpdated.Format(time.RFC1123))
+	}
+	w.Flush()
+}
+
+// writeOrphansCSV writes the orphan apps as CSV, header included.
+func writeOrphansCSV(w io.Writer, orphans []OrphanApp) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"App ID", "Type", "Environment ID", "Environment", "Last Updated"})
+	for _, o := range orphans {
+		cw.Write([]string{o.AppID, o.AppType, o.EnvID, o.EnvName, o.LastUpdated.Format(time.RFC3339)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// unsafeFileNameChars matches the characters not allowed in generated file names.
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeFileName turns an arbitrary name (e.g. an environment name) into a safe file name.
+func sanitizeFileName(name string) string {
+	safe := strings.Trim(unsafeFileNameChars.ReplaceAllString(name, "_"), "._")
+	if safe == "" {
+		return "unnamed"
+	}
+	return safe
+}
+
+// orphanEnv is a non-production environment searched for orphan apps.
+type orphanEnv struct {
+	ID   string
+	Name string
+}
+
+// envFileNames returns the report file name, without extension, of each environment by ID.
+// Environments whose sanitized names collide, such as "Prod/EU" and "Prod_EU", or only
+// differ in case, get their ID appended so that no report overwrites another.
+func envFileNames(envs []orphanEnv) map[string]string {
+	names := make(map[string]string, len(envs))
+	taken := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		name := sanitizeFileName(env.Name)
+		if taken[strings.ToLower(name)] {
+			name += "_" + sanitizeFileName(env.ID)
+		}
+		for i := 2; taken[strings.ToLower(name)]; i++ {
+			name = fmt.Sprintf("%s_%s_%d", sanitizeFileName(env.Name), sanitizeFileName(env.ID), i)
+		}
+		taken[strings.ToLower(name)] = true
+		names[env.ID] = name
+	}
+	return names
+}
+
+// writeOrphansByEnv writes one file per environment in dir, named after the environment,
+// in the given format (json or csv). Environments without orphans get an empty report.
+func writeOrphansByEnv(dir, format string, envs []orphanEnv, orphans []OrphanApp) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating output directory: %w", err)
+	}
+	byEnv := make(map[string][]OrphanApp, len(envs))
+	for _, o := range orphans {
+		byEnv[o.EnvID] = append(byEnv[o.EnvID], o)
+	}
+	fileNames := envFileNames(envs)
+	for _, env := range envs {
+		path := filepath.Join(dir, fileNames[env.ID]+"."+format)
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %w", path, err)
+		}
+		envOrphans := byEnv[env.ID]
+		if format == output.JSON {
+			if envOrphans == nil {
+				envOrphans = []OrphanApp{}
+			}
+			printJSON(f, envOrphans)
+		} else if err := writeOrphansCSV(f, envOrphans); err != nil {
+			f.Close()
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %d apps to %s\n", len(envOrphans), path)
+	}
+	return nil
+}
+
+// appsCmd represents the apps command
+var appsCmd = &cobra.Command{
+	Use:   "apps",
+	Short: "Analyze applications across environments",
+	Long:  `Analyze the applications deployed across the environments of a Business Group.`,
+}
+
+// appsOrphansCmd represents the apps orphans command
+var appsOrphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "List idle apps in non-production environments",
+	Long: `List the apps deployed in the non-production environments of a Business Group
+that received no traffic over the given window. These apps are decommission candidates.
+
+Apps are listed oldest first, based on the time they were last updated. When the request
+count of some apps cannot be retrieved, their number is printed to stderr and the command
+exits with status 2.
+
+Use --split-by-env --out-dir <dir> with --output json or csv to write one file per
+environment, named after it, instead of printing the report. Environments whose names
+map to the same file name get their ID appended to it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		orgID, _ := cmd.Flags().GetString("org")
+		window, _ := cmd.Flags().GetString("window")
+		format, _ := cmd.Flags().GetString("output")
+		splitByEnv, _ := cmd.Flags().GetBool("split-by-env")
+		outDir, _ := cmd.Flags().GetString("out-dir")
+
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON, output.CSV); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := anypoint.ValidateWindow(window); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if splitByEnv && (outDir == "" || format == output.Table) {
+			fmt.Println("--split-by-env requires --out-dir and --output json or csv.")
+			return
+		}
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+		if orgID == "" {
+			orgID = client.Org
+		}
+		if orgID == "" {
+			fmt.Println("Please provide a business group ID using the --org flag.")
+			return
+		}
+
+		environments, err := client.GetEnvironments(ctx, orgID)
+		if err != nil {
+			fmt.Printf("Error retrieving environments: %v\n", err)
+			return
+		}
+
+		var orphans []OrphanApp
+		var failed, unmonitorable []anypoint.App
+		var envs []orphanEnv
+		for _, env := range environments {
+			if env.GetIsProduction() {
+				continue
+			}
+			envs = append(envs, orphanEnv{ID: env.GetId(), Name: env.GetName()})
+			envOrphans, envFailed, skipped, err := findOrphansInEnv(ctx, client, orgID, env.GetId(), env.GetName(), window)
+			if err != nil {
+				anypoint.Logger.Error("error retrieving apps of environment", "env", env.GetName(), "err", err)
+				continue
+			}
+			orphans = append(orphans, envOrphans...)
+			failed = append(failed, envFailed...)
+			unmonitorable = append(unmonitorable, skipped...)
+		}
+		checkUnknownTargetTypes(unmonitorable, false)
+
+		// Oldest idle apps first.
+		sort.SliceStable(orphans, func(i, j int) bool {
+			return orphans[i].LastUpdated.Before(orphans[j].LastUpdated)
+		})
+
+		switch {
+		case splitByEnv:
+			if err := writeOrphansByEnv(outDir, format, envs, orphans); err != nil {
+				fmt.Printf("Error writing reports: %v\n", err)
+			}
+		case format == output.CSV:
+			if err := writeOrphansCSV(os.Stdout, orphans); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing CSV output: %v\n", err)
+			}
+		case format == output.JSON:
+			if orphans == nil {
+				orphans = []OrphanApp{}
+			}
+			printJSON(os.Stdout, orphans)
+		case len(orphans) == 0:
+			fmt.Printf("No idle apps found in non-production environments over the last %s.\n", window)
+		default:
+			printOrphansTable(orphans)
+		}
+
+		// The apps that could not be checked are not listed, so they must not pass for active ones.
+		if len(failed) > 0 {
+			fmt.Fprintf(os.Stderr, "%d apps could not be checked.\n", len(failed))
+			os.Exit(exitAppsFailed)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(appsCmd)
+	appsCmd.AddCommand(appsOrphansCmd)
+	appsOrphansCmd.Flags().StringP("org", "o", "", "Business Group ID (defaults to the persisted one)")
+	appsOrphansCmd.Flags().String("window", "30d", "Time window without traffic (e.g., 7d, 30d)")
+	appsOrphansCmd.Flags().Bool("split-by-env", false, "Write one report file per environment instead of printing the report")
+	appsOrphansCmd.Flags().String("out-dir", "", "Directory the per-environment reports are written to (with --split-by-env)")
+	appsOrphansCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
+}
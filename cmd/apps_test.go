@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOrphansByEnvCollidingNames(t *testing.T) {
+	dir := t.TempDir()
+	envs := []orphanEnv{
+		{ID: "env-1", Name: "Prod/EU"},
+		{ID: "env-2", Name: "Prod_EU"},
+		{ID: "env-3", Name: "prod eu"},
+	}
+	orphans := []OrphanApp{
+		{AppID: "orders", EnvID: "env-1", EnvName: "Prod/EU"},
+		{AppID: "billing", EnvID: "env-2", EnvName: "Prod_EU"},
+	}
+
+	if err := writeOrphansByEnv(dir, "json", envs, orphans); err != nil {
+		t.Fatalf("writeOrphansByEnv: %v", err)
+	}
+
+	want := map[string]string{
+		"Prod_EU.json":       "orders",
+		"Prod_EU_env-2.json": "billing",
+		"prod_eu_env-3.json": "",
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("wrote %d files, want %d", len(entries), len(want))
+	}
+	for name, appID := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("missing report: %v", err)
+			continue
+		}
+		var got []OrphanApp
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		switch {
+		case appID == "" && len(got) != 0:
+			t.Errorf("%s lists %v, want no app", name, got)
+		case appID != "" && (len(got) != 1 || got[0].AppID != appID):
+			t.Errorf("%s lists %v, want %s", name, got, appID)
+		}
+	}
+}
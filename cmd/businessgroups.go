@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+// filterBusinessGroups returns the business groups whose name contains the given
+// substring, case-insensitively. An empty substring matches every group.
+func filterBusinessGroups(groups []anypoint.BusinessGroup, nameContains string) []anypoint.BusinessGroup {
+	if nameContains == "" {
+		return groups
+	}
+	needle := strings.ToLower(nameContains)
+	var filtered []anypoint.BusinessGroup
+	for _, bg := range groups {
+		if strings.Contains(strings.ToLower(bg.Name), needle) {
+			filtered = append(filtered, bg)
+		}
+	}
+	return filtered
+}
+
+// formatEntitlements renders an entitlements summary as "name=value" pairs sorted by name.
+func formatEntitlements(entitlements map[string]float64) string {
+	if len(entitlements) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(entitlements))
+	for name := range entitlements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%g", name, entitlements[name]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// printBusinessGroupsTable prints the business groups using tabwriter for alignment.
+func printBusinessGroupsTable(groups []anypoint.BusinessGroup) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tMaster\tEnvironments\tEntitlements")
+	fmt.Fprintln(w, "--\t----\t------\t------------\t------------")
+	for _, bg := range groups {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%s\n", bg.ID, bg.Name, bg.IsMaster, bg.EnvironmentCount, formatEntitlements(bg.Entitlements))
+	}
+	w.Flush()
+}
+
+// printBusinessGroupsTree prints the business groups as a tree, each sub business group
+// indented below its parent. Groups whose parent is not listed are printed at the top level.
+func printBusinessGroupsTree(groups []anypoint.BusinessGroup) {
+	listed := make(map[string]bool, len(groups))
+	for _, bg := range groups {
+		listed[bg.ID] = true
+	}
+	children := make(map[string][]anypoint.BusinessGroup)
+	var roots []anypoint.BusinessGroup
+	for _, bg := range groups {
+		if bg.ParentID != "" && listed[bg.ParentID] {
+			children[bg.ParentID] = append(children[bg.ParentID], bg)
+		} else {
+			roots = append(roots, bg)
+		}
+	}
+	var walk func(bg anypoint.BusinessGroup, depth int)
+	walk = func(bg anypoint.BusinessGroup, depth int) {
+		fmt.Printf("%s%s (ID: %s)\n", strings.Repeat("  ", depth), bg.Name, bg.ID)
+		for _, child := range children[bg.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, bg := range roots {
+		walk(bg, 0)
+	}
+}
+
+// businessGroupsCmd represents the business-groups command
+var businessGroupsCmd = &cobra.Command{
+	Use:     "business-groups",
+	Aliases: []string{"business-group"},
+	Short:   "List Business Groups",
+	Long: `List a Business Group and its sub Business Groups along with their metadata:
+whether it is the master org, its environment count and an entitlements summary.
+
+Use --recursive to list the whole tree of nested sub Business Groups (down to 10 levels)
+rather than the direct ones only.
+
+Use --tree to print them as an indented tree with their IDs instead, and
+'business-groups use <id>' to persist the one to work with.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		orgID, _ := cmd.Flags().GetString("org")
+		format, _ := cmd.Flags().GetString("output")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		tree, _ := cmd.Flags().GetBool("tree")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+		if orgID == "" {
+			orgID = client.Org
+		}
+		if orgID == "" {
+			fmt.Println("Please provide a business group ID using the --org flag.")
+			return
+		}
+
+		var groups []anypoint.BusinessGroup
+		if recursive {
+			var nodes []anypoint.BusinessGroupNode
+			nodes, err = client.GetBusinessGroupTree(ctx, orgID)
+			for _, node := range nodes {
+				groups = append(groups, node.BusinessGroup)
+			}
+		} else {
+			groups, err = client.GetBusinessGroups(ctx, orgID)
+		}
+		if err != nil {
+			fmt.Printf("Error retrieving business groups: %v\n", err)
+			return
+		}
+		groups = filterBusinessGroups(groups, nameContains)
+
+		if format == output.JSON {
+			if groups == nil {
+				groups = []anypoint.BusinessGroup{}
+			}
+			printJSON(os.Stdout, groups)
+			return
+		}
+
+		if len(groups) == 0 {
+			fmt.Println("No business groups found.")
+			return
+		}
+		if tree {
+			printBusinessGroupsTree(groups)
+			return
+		}
+		printBusinessGroupsTable(groups)
+	},
+}
+
+// businessGroupsUseCmd represents the business-groups use command
+var businessGroupsUseCmd = &cobra.Command{
+	Use:   "use <id>",
+	Short: "Persist the Business Group to work with",
+	Long: `Persist the Business Group used by default by the other commands, like 'environment'
+persists the environment. The persisted environment is cleared when the Business Group
+changes, as it belongs to the previous one.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		orgID := args[0]
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+
+		// Check that the business group exists and is accessible.
+		detail, err := client.GetBusinessGroup(ctx, orgID)
+		if err != nil {
+			fmt.Printf("Error retrieving business group: %v\n", err)
+			return
+		}
+
+		if client.Org != orgID {
+			client.Env = ""
+		}
+		client.SetOrg(orgID)
+		fmt.Printf("Selected business group: %s (ID: %s)\n", detail.GetName(), orgID)
+		if client.IsEnvEmpty() {
+			fmt.Println("Run 'environment' to select an environment of this business group.")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(businessGroupsCmd)
+	businessGroupsCmd.AddCommand(businessGroupsUseCmd)
+	businessGroupsCmd.Flags().StringP("org", "o", "", "Business Group ID to list from (defaults to the persisted one)")
+	businessGroupsCmd.Flags().Bool("tree", false, "Print the Business Groups as an indented tree")
+	businessGroupsCmd.Flags().Bool("recursive", false, "List all the nested sub Business Groups, not only the direct ones")
+	businessGroupsCmd.Flags().String("name-contains", "", "Only list Business Groups whose name contains this text (case-insensitive)")
+	businessGroupsCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// controlPlanes lists the accepted values of the --controlplane flag.
+var controlPlanes = []string{"us", "eu", "gov"}
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the autocompletion script for the specified shell",
+	Long: `Generate the autocompletion script for MuleTracker for the specified shell.
+
+To load completions in the current bash session:
+  source <(muletracker-cli completion bash)
+
+To load completions in the current zsh session:
+  source <(muletracker-cli completion zsh)
+
+To load completions in the current fish session:
+  muletracker-cli completion fish | source
+
+To load completions in the current PowerShell session:
+  muletracker-cli completion powershell | Out-String | Invoke-Expression`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		default:
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+// completeControlPlane completes the values of the --controlplane flag.
+func completeControlPlane(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return controlPlanes, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeValues returns a completion function suggesting the given fixed values.
+func completeValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeOutput completes the values of the global --output flag, including the
+// formats specific to the monitor command.
+func completeOutput(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if cmd == monitorCmd {
+		return []string{"table", "json", "csv", "influx-line", "timeseries", "markdown"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return []string{"table", "json", "csv"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePersisted returns a completion function suggesting the value persisted under key, if any.
+func completePersisted(key string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if v := viper.GetString(config.Key(key)); v != "" {
+			return []string{v}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mulesoft-anypoint/anypoint-client-go/org"
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces sensitive values in exported configuration.
+const redactedValue = "********"
+
+// defaultRedactedKeys lists the sensitive configuration keys masked by default.
+var defaultRedactedKeys = []string{"clientSecret", "accessToken", "adminAccessToken", "influx-write-token", "slack-webhook"}
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the persisted configuration",
+	Long:  `Inspect and manage the configuration persisted by MuleTracker (by default at $XDG_CONFIG_HOME/muletracker/config.yaml).`,
+}
+
+// configExportCmd represents the config export command
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the persisted configuration as YAML",
+	Long: `Export the persisted configuration as YAML on stdout so it can be shared or copied to another machine.
+
+Sensitive keys are masked. Use --redact to choose exactly which keys are masked, e.g.:
+  --redact clientSecret,accessToken
+Pass --redact "" to export every value in clear text.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		redact, _ := cmd.Flags().GetStringSlice("redact")
+
+		settings := redactSettings(viper.AllSettings(), redact)
+		out, err := yaml.Marshal(settings)
+		if err != nil {
+			fmt.Printf("Error exporting configuration: %v\n", err)
+			return
+		}
+		os.Stdout.Write(out)
+	},
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration of the active profile",
+	Long: `Show the configuration the commands use for the active profile, once the MULETRACKER_*
+environment variables and the persisted configuration are merged, and whether it is complete.
+Secrets are masked, only their first and last 4 characters are shown.
+
+It works with an expired token, which is reported rather than refreshed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		status := "Complete"
+		if client, err := anypoint.GetClientFromContext(anypoint.WithSkipTokenExpiration()); err != nil {
+			status = err.Error()
+		} else if client.IsTokenExpired() {
+			status = "Complete, token expired"
+		}
+
+		controlPlane := viper.GetString(config.Key("controlplane"))
+		if controlPlane == "" {
+			controlPlane = anypoint.ControlPlane(viper.GetInt(config.Key("serverIndex")))
+		}
+		PrintSimpleResults("Effective Configuration:", map[string]interface{}{
+			"Profile":           config.ActiveProfile(),
+			"Config File":       valueOrUnset(config.Path()),
+			"Control Plane":     controlPlane,
+			"Base URL":          valueOrUnset(viper.GetString(config.Key("baseUrl"))),
+			"Client ID":         valueOrUnset(viper.GetString(config.Key("clientId"))),
+			"Client Secret":     maskSecret(config.GetSecret(config.Key("clientSecret"))),
+			"Access Token":      maskSecret(config.GetSecret(config.Key("accessToken"))),
+			"Token Expires At":  valueOrUnset(viper.GetString(config.Key("expiresAt"))),
+			"InfluxDB ID":       viper.GetInt(config.Key("influxdbId")),
+			"InfluxDB Database": valueOrUnset(viper.GetString(config.Key("influxdbDatabase"))),
+			"Business Group Id": valueOrUnset(viper.GetString(config.Key("org"))),
+			"Environment Id":    valueOrUnset(viper.GetString(config.Key("env"))),
+			"Status":            status,
+		})
+	},
+}
+
+// configResetCmd represents the config reset command
+var configResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Clear the persisted session and credentials of the active profile",
+	Long: `Clear the settings persisted for the active profile when they get into a bad state:
+the session opened by 'connect' (access token, expiration, InfluxDB datasource, org and env)
+and the connected app credentials (client ID and secret, control plane).
+
+Use --keep-credentials to only clear the session; the next command logs in again with the
+kept credentials. The other profiles are left untouched.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		keepCredentials, _ := cmd.Flags().GetBool("keep-credentials")
+
+		if err := config.ResetProfile(keepCredentials); err != nil {
+			fmt.Printf("Error resetting configuration: %v\n", err)
+			return
+		}
+		if keepCredentials {
+			fmt.Printf("Session of profile %s cleared in %s.\n", config.ActiveProfile(), config.Path())
+		} else {
+			fmt.Printf("Profile %s reset in %s. Run 'connect' to store new credentials.\n", config.ActiveProfile(), config.Path())
+		}
+	},
+}
+
+// configSetKeys lists the settings that can be changed with config set.
+var configSetKeys = []string{"org", "env", "controlplane"}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <org|env|controlplane> <value>",
+	Short: "Persist the default org, env or control plane",
+	Long: `Persist a default of the active profile, used by the commands when the corresponding
+flag is omitted:
+  org           business group, by ID or name
+  env           environment of the persisted business group, by ID or name
+  controlplane  control plane the next 'connect' logs in to (eu, us, gov)
+
+The business group and environment are checked against the Anypoint Platform. Changing the
+business group clears the persisted environment, which belongs to the previous one.`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case len(args) == 0:
+			return configSetKeys, cobra.ShellCompDirectiveNoFileComp
+		case len(args) == 1 && args[0] == "controlplane":
+			return controlPlanes, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		key, value := strings.ToLower(args[0]), args[1]
+
+		if key == "controlplane" {
+			value = strings.ToLower(value)
+			if anypoint.ServerIndex(value) == -1 {
+				fmt.Printf("Invalid control plane %q. Valid values are %s.\n", value, strings.Join(controlPlanes, ", "))
+				return
+			}
+			viper.Set(config.Key("controlplane"), value)
+			if err := config.SaveConfig(); err != nil {
+				fmt.Printf("Error saving configuration: %v\n", err)
+				return
+			}
+			fmt.Printf("Control plane set to %s. Run 'connect' to log in to it.\n", value)
+			return
+		}
+		if key != "org" && key != "env" {
+			fmt.Printf("Invalid key %q. Valid keys are %s.\n", key, strings.Join(configSetKeys, ", "))
+			return
+		}
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+
+		if key == "org" {
+			orgID, _, err := resolveOrgEnv(ctx, client, value, "")
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			detail, err := client.GetBusinessGroup(ctx, orgID)
+			if err != nil {
+				fmt.Printf("Error retrieving business group: %v\n", err)
+				return
+			}
+			if orgID != client.Org && client.Env != "" {
+				client.Env = ""
+				fmt.Println("Persisted environment cleared, it belongs to the previous business group.")
+			}
+			client.SetOrg(orgID)
+			fmt.Printf("Business group set to %s (ID: %s)\n", detail.GetName(), orgID)
+			return
+		}
+
+		if client.Org == "" {
+			fmt.Println("No business group persisted. Run 'config set org' first.")
+			return
+		}
+		environments, err := client.GetEnvironments(ctx, client.Org)
+		if err != nil {
+			fmt.Printf("Error retrieving environments: %v\n", err)
+			return
+		}
+		var env org.Environment
+		if guidPattern.MatchString(value) {
+			env, err = findEnvironment(environments, value, "")
+		} else {
+			env, err = findEnvironment(environments, "", value)
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		client.SetEnv(env.GetId())
+		fmt.Printf("Environment set to %s (ID: %s)\n", env.GetName(), env.GetId())
+	},
+}
+
+// unsetValue is shown in place of the configuration values that are not set.
+const unsetValue = "(not set)"
+
+// valueOrUnset returns value, or unsetValue when it is empty.
+func valueOrUnset(value string) string {
+	if value == "" {
+		return unsetValue
+	}
+	return value
+}
+
+// maskSecret masks a secret, keeping its first and last 4 characters. Short secrets
+// are fully masked.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return unsetValue
+	}
+	if len(secret) <= 12 {
+		return redactedValue
+	}
+	return secret[:4] + "..." + secret[len(secret)-4:]
+}
+
+// redactSettings returns a copy of settings where the given keys are masked, at any
+// depth, e.g. in the named profiles or the saved monitor invocation. A key matches a
+// setting by name or by its full dotted path, case-insensitively since viper lower-cases them.
+func redactSettings(settings map[string]interface{}, keys []string) map[string]interface{} {
+	masked := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			masked[key] = true
+		}
+	}
+	return redactMap(settings, masked, "")
+}
+
+// redactMap masks the settings of m, whose keys are under the given path prefix.
+func redactMap(m map[string]interface{}, masked map[string]bool, prefix string) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		path := prefix + strings.ToLower(k)
+		if nested, ok := settingsMap(v); ok {
+			redacted[k] = redactMap(nested, masked, path+".")
+		} else if (masked[strings.ToLower(k)] || masked[path]) && fmt.Sprintf("%v", v) != "" {
+			redacted[k] = redactedValue
+		} else {
+			redacted[k] = v
+		}
+	}
+	return redacted
+}
+
+// settingsMap returns v as a nested section of the settings, if it is one.
+func settingsMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[string]string:
+		nested := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			nested[k] = v
+		}
+		return nested, true
+	}
+	return nil, false
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configResetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configResetCmd.Flags().Bool("keep-credentials", false, "Only clear the session, keeping the connected app credentials and control plane")
+	configExportCmd.Flags().StringSlice("redact", defaultRedactedKeys, "Comma-separated list of configuration keys to mask")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import "testing"
+
+func TestRedactSettingsNested(t *testing.T) {
+	settings := map[string]interface{}{
+		"clientsecret": "top-secret",
+		"org":          "my-org",
+		"profiles": map[string]interface{}{
+			"ci": map[string]interface{}{
+				"clientsecret":     "ci-secret",
+				"adminaccesstoken": "ci-token",
+				"clientid":         "ci-client",
+			},
+		},
+		"lastmonitor": map[string]string{
+			"influx-write-token": "influx-token",
+			"filter":             "empty",
+		},
+	}
+
+	redacted := redactSettings(settings, defaultRedactedKeys)
+
+	if got := redacted["clientsecret"]; got != redactedValue {
+		t.Errorf("clientsecret = %v, want it masked", got)
+	}
+	if got := redacted["org"]; got != "my-org" {
+		t.Errorf("org = %v, want it kept", got)
+	}
+	ci := redacted["profiles"].(map[string]interface{})["ci"].(map[string]interface{})
+	for _, key := range []string{"clientsecret", "adminaccesstoken"} {
+		if ci[key] != redactedValue {
+			t.Errorf("profiles.ci.%s = %v, want it masked", key, ci[key])
+		}
+	}
+	if ci["clientid"] != "ci-client" {
+		t.Errorf("profiles.ci.clientid = %v, want it kept", ci["clientid"])
+	}
+	last := redacted["lastmonitor"].(map[string]interface{})
+	if last["influx-write-token"] != redactedValue || last["filter"] != "empty" {
+		t.Errorf("lastmonitor = %v, want only the token masked", last)
+	}
+	// The settings themselves are left untouched.
+	if settings["profiles"].(map[string]interface{})["ci"].(map[string]interface{})["clientsecret"] != "ci-secret" {
+		t.Error("redactSettings modified its input")
+	}
+}
+
+func TestRedactSettingsByPath(t *testing.T) {
+	settings := map[string]interface{}{
+		"clientid": "default-client",
+		"profiles": map[string]interface{}{
+			"ci": map[string]interface{}{"clientid": "ci-client"},
+		},
+	}
+	redacted := redactSettings(settings, []string{"Profiles.CI.clientId"})
+	if redacted["clientid"] != "default-client" {
+		t.Errorf("clientid = %v, want it kept", redacted["clientid"])
+	}
+	if got := redacted["profiles"].(map[string]interface{})["ci"].(map[string]interface{})["clientid"]; got != redactedValue {
+		t.Errorf("profiles.ci.clientid = %v, want it masked", got)
+	}
+}
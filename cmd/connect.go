@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -16,29 +21,49 @@ var connectCmd = &cobra.Command{
 	Long:  `Authenticate and establish a connection to the Anypoint Platform using your credentials.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
+		format, _ := cmd.Flags().GetString("output")
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON); err != nil {
+			fmt.Println(err)
+			return
+		}
 
-		// Attempt to get clientId and clientSecret from flags;
-		// if not provided, read them from persisted configuration.
+		// Settings are resolved as: flag > MULETRACKER_* environment variable >
+		// configuration file > default. Viper applies the environment over the file.
 		clientId, _ := cmd.Flags().GetString("clientId")
 		if clientId == "" {
-			clientId = viper.GetString("clientId")
+			clientId = viper.GetString(config.Key("clientId"))
 		}
 
 		clientSecret, _ := cmd.Flags().GetString("clientSecret")
 		if clientSecret == "" {
-			clientSecret = viper.GetString("clientSecret")
+			clientSecret = config.GetSecret(config.Key("clientSecret"))
 		}
 
-		// Attempt to get controlplane from flag; if not provided, read from configuration.
+		// Attempt to get controlplane from flag; if not provided, read from the environment or configuration.
 		controlPlane, _ := cmd.Flags().GetString("controlplane")
 		if controlPlane == "" {
-			controlPlane = viper.GetString("controlplane")
+			controlPlane = viper.GetString(config.Key("controlplane"))
 		}
 		// If still empty, default to "us"
 		if controlPlane == "" {
 			controlPlane = "us"
 		}
 
+		// A private control plane is reached through its base URL instead.
+		// Choosing a control plane explicitly goes back to the public ones.
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		if baseURL == "" && !cmd.Flags().Changed("controlplane") {
+			baseURL = viper.GetString(config.Key("baseUrl"))
+		}
+		if baseURL != "" {
+			if err := anypoint.ValidateBaseURL(baseURL); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		viper.Set(config.Key("baseUrl"), baseURL)
+
 		// Validate that we have credentials.
 		if clientId == "" || clientSecret == "" {
 			fmt.Println("clientId and clientSecret are required. Please provide them via flags or ensure they are persisted in configuration.")
@@ -46,23 +71,38 @@ var connectCmd = &cobra.Command{
 		}
 
 		// Validate control plane and determine the server index.
-		serverIndex := cplane2serverindex(controlPlane)
+		serverIndex := anypoint.ServerIndex(controlPlane)
 		if serverIndex == -1 {
 			fmt.Println("Invalid control plane. Valid values are 'eu', 'us', or 'gov'.")
 			return
 		}
 
 		// Create the client; this will obtain an access token and set its expiration.
-		client, err := anypoint.NewClient(ctx, serverIndex, clientId, clientSecret)
+		var opts []anypoint.ClientOption
+		if refreshInfluxID, _ := cmd.Flags().GetBool("refresh-influx-id"); refreshInfluxID {
+			opts = append(opts, anypoint.WithRefreshInfluxID())
+		}
+		client, err := anypoint.NewClient(ctx, serverIndex, clientId, clientSecret, opts...)
 		if err != nil {
 			fmt.Printf("Error connecting to Anypoint: %v\n", err)
 			return
 		}
 
-		// Display the client info in a colorful way.
-		PrintClientInfo(client)
+		// Display the client info in a colorful way, or as JSON. Messages then go to stderr
+		// to keep stdout parseable.
+		printClientInfoAs(ctx, format, client)
+		info := io.Writer(os.Stdout)
+		if format != output.Table {
+			info = os.Stderr
+		}
 
-		fmt.Printf("Successfully connected. Access token valid until %s.\n", client.ExpiresAt.Format(time.RFC1123))
+		fmt.Fprintf(info, "Successfully connected. Access token valid until %s.\n", client.ExpiresAt.Format(time.RFC1123))
+
+		// Monitoring queries fail later if the datasource doesn't answer, so check it now.
+		if err := client.CheckDatasource(ctx); err != nil {
+			fmt.Fprintf(info, "Warning: %v\n", err)
+			fmt.Fprintln(info, "Monitoring queries will fail. Check that the connected app has the Monitoring Viewer permission on the business group, or reconnect with --refresh-influx-id if the datasource changed.")
+		}
 	},
 }
 
@@ -71,29 +111,7 @@ func init() {
 	connectCmd.Flags().StringP("clientId", "i", "", "Anypoint Platform connected app client id")
 	connectCmd.Flags().StringP("clientSecret", "s", "", "Anypoint Platform connected app client secret")
 	connectCmd.Flags().StringP("controlplane", "c", "", "Control plane to use (eu, us, gov)")
-}
-
-// cplane2serverindex converts control plane name to server index.
-func cplane2serverindex(cplane string) int {
-	if cplane == "eu" {
-		return 1
-	} else if cplane == "us" {
-		return 0
-	} else if cplane == "gov" {
-		return 2
-	}
-	return -1 // Return -1 for invalid control plane
-}
-
-func serverindex2cplane(index int) string {
-	switch index {
-	case 0:
-		return "us"
-	case 1:
-		return "eu"
-	case 2:
-		return "gov"
-	default:
-		return "unknown"
-	}
+	connectCmd.Flags().String("base-url", "", "Base URL of a private or dedicated control plane, e.g. https://anypoint.example.com (takes precedence over --controlplane)")
+	connectCmd.Flags().Bool("refresh-influx-id", false, "Retrieve the InfluxDB ID of the monitoring datasource again instead of reusing the persisted one")
+	connectCmd.RegisterFlagCompletionFunc("controlplane", completeControlPlane)
 }
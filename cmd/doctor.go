@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// doctorChecks records the outcome of the checks of the doctor command. The checks are
+// numbered so that they are listed in the order they ran.
+type doctorChecks struct {
+	results map[string]interface{}
+	failed  bool
+}
+
+// pass records a successful check.
+func (d *doctorChecks) pass(name, format string, args ...interface{}) {
+	d.add(name, "PASS: "+fmt.Sprintf(format, args...))
+}
+
+// fail records a failed check.
+func (d *doctorChecks) fail(name, format string, args ...interface{}) {
+	d.failed = true
+	d.add(name, "FAIL: "+fmt.Sprintf(format, args...))
+}
+
+// skip records a check that could not run because an earlier one failed.
+func (d *doctorChecks) skip(name string) {
+	d.add(name, "SKIPPED: fix the failed checks above first")
+}
+
+func (d *doctorChecks) add(name, result string) {
+	d.results[fmt.Sprintf("%d. %s", len(d.results)+1, name)] = result
+}
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common setup problems",
+	Long: `Check, in order, the configuration file, the connected app credentials, the access token,
+the business group and the environment, the InfluxDB ID and the monitoring datasource, and
+print a pass/fail line for each with a hint on how to fix it.
+
+The checks that need the Anypoint Platform are skipped once an earlier one failed. The
+command exits with a non-zero status if any check failed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		checks := &doctorChecks{results: make(map[string]interface{})}
+		defer func() {
+			PrintSimpleResults("Diagnosis:", checks.results)
+			if checks.failed {
+				os.Exit(1)
+			}
+		}()
+
+		// The configuration file holds the credentials and the session.
+		if info, err := os.Stat(config.Path()); err != nil {
+			checks.fail("Config File", "%v; run 'init' to set up MuleTracker", err)
+		} else if perm := info.Mode().Perm(); perm&0o077 != 0 {
+			checks.fail("Config File", "%s is readable by other users (%#o); run 'chmod 600 %s'", config.Path(), perm, config.Path())
+		} else {
+			checks.pass("Config File", "%s (%#o)", config.Path(), perm)
+		}
+
+		if viper.GetString(config.Key("clientId")) == "" || config.GetSecret(config.Key("clientSecret")) == "" {
+			checks.fail("Credentials", "no connected app client ID and secret; run 'connect' with --clientId and --clientSecret")
+			for _, name := range []string{"Access Token", "Business Group", "Environment", "InfluxDB ID", "Monitoring Datasource"} {
+				checks.skip(name)
+			}
+			return
+		}
+		checks.pass("Credentials", "client ID %s", viper.GetString(config.Key("clientId")))
+
+		// An expired token is refreshed, as any other command would.
+		client, err := anypoint.GetClientFromContext(anypoint.WithSkipTokenExpiration())
+		if err == nil && client.IsTokenExpired() {
+			client, err = anypoint.GetClientFromContext()
+		}
+		if err != nil {
+			checks.fail("Access Token", "%v", err)
+			for _, name := range []string{"Business Group", "Environment", "InfluxDB ID", "Monitoring Datasource"} {
+				checks.skip(name)
+			}
+			return
+		}
+		checks.pass("Access Token", "valid until %s", client.ExpiresAt.Format(time.RFC1123))
+
+		orgResolved := false
+		if client.Org == "" {
+			checks.fail("Business Group", "not set; run 'business-groups use <id>' or 'config set org <id>'")
+		} else if bg, err := client.GetBusinessGroup(ctx, client.Org); err != nil {
+			checks.fail("Business Group", "%s cannot be retrieved: %v", client.Org, err)
+		} else {
+			orgResolved = true
+			checks.pass("Business Group", "%s (ID: %s)", bg.GetName(), client.Org)
+		}
+
+		switch {
+		case client.Env == "":
+			checks.fail("Environment", "not set; run 'environment' or 'config set env <id>'")
+		case !orgResolved:
+			checks.skip("Environment")
+		default:
+			environments, err := client.GetEnvironments(ctx, client.Org)
+			if err != nil {
+				checks.fail("Environment", "cannot retrieve the environments: %v", err)
+				break
+			}
+			env, err := findEnvironment(environments, client.Env, "")
+			if err != nil {
+				checks.fail("Environment", "%v in the business group; run 'environment' to select one", err)
+				break
+			}
+			checks.pass("Environment", "%s (ID: %s)", env.GetName(), client.Env)
+		}
+
+		if client.InfluxDbId == 0 {
+			checks.fail("InfluxDB ID", "not set; run 'connect --refresh-influx-id'")
+			checks.skip("Monitoring Datasource")
+			return
+		}
+		checks.pass("InfluxDB ID", "%d", client.InfluxDbId)
+
+		if err := client.CheckDatasource(ctx); err != nil {
+			checks.fail("Monitoring Datasource", "%v; check that the connected app has the Monitoring Viewer permission", err)
+			return
+		}
+		checks.pass("Monitoring Datasource", "answers")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -6,23 +6,77 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/mulesoft-anypoint/anypoint-client-go/org"
 	"github.com/mulesoft-anypoint/muletracker-cli/anypoint" // adjust the import path as needed
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
 	"github.com/spf13/cobra"
 )
 
+// findEnvironment returns the environment with the given ID or, when id is empty, the
+// only one with the given name, compared case-insensitively.
+func findEnvironment(environments []org.Environment, id, name string) (org.Environment, error) {
+	var matches []org.Environment
+	for _, env := range environments {
+		if (id != "" && env.GetId() == id) || (id == "" && strings.EqualFold(env.GetName(), name)) {
+			matches = append(matches, env)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case id != "":
+		return org.Environment{}, fmt.Errorf("no environment with ID %s", id)
+	case len(matches) == 0:
+		return org.Environment{}, fmt.Errorf("no environment named %s", name)
+	}
+	ids := make([]string, 0, len(matches))
+	for _, env := range matches {
+		ids = append(ids, env.GetId())
+	}
+	return org.Environment{}, fmt.Errorf("%d environments are named %s, use the ID of one of them: %s", len(matches), name, strings.Join(ids, ", "))
+}
+
+// EnvironmentResult summarizes an environment of a Business Group.
+type EnvironmentResult struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	IsProduction bool   `json:"isProduction"`
+}
+
+// printEnvironmentsTable prints the environments using tabwriter for alignment.
+func printEnvironmentsTable(environments []EnvironmentResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tID\tType\tProduction")
+	fmt.Fprintln(w, "----\t--\t----\t----------")
+	for _, env := range environments {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", env.Name, env.ID, env.Type, env.IsProduction)
+	}
+	w.Flush()
+}
+
 // environmentsCmd represents the environment command
 var environmentsCmd = &cobra.Command{
 	Use:   "environment",
 	Short: "Get Environment Details",
-	Long:  `Retrieve and display Environment details for a specific Business Group, then allow selection of one to persist.`,
+	Long: `Retrieve and display Environment details for a specific Business Group, then allow selection of one to persist.
+
+Use --env-id or --env-name to persist an environment without being prompted, e.g. in scripts.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 		businessGroupID, _ := cmd.Flags().GetString("org")
+		envID, _ := cmd.Flags().GetString("env-id")
+		envName, _ := cmd.Flags().GetString("env-name")
 		if businessGroupID == "" {
 			fmt.Println("Please provide a business group ID using the --org flag.")
 			return
 		}
+		if envID != "" && envName != "" {
+			fmt.Println("--env-id and --env-name cannot be combined.")
+			return
+		}
 
 		// Retrieve the authenticated client.
 		client, err := anypoint.GetClientFromContext()
@@ -46,6 +100,19 @@ var environmentsCmd = &cobra.Command{
 			return
 		}
 
+		// Select the environment given by flag without prompting.
+		if envID != "" || envName != "" {
+			selectedEnv, err := findEnvironment(environments, envID, envName)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			client.SetOrg(businessGroupID)
+			client.SetEnv(selectedEnv.GetId())
+			fmt.Printf("Selected environment: %s (ID: %s)\n", selectedEnv.GetName(), selectedEnv.GetId())
+			return
+		}
+
 		// List the available environments.
 		fmt.Println("Environments:")
 		for idx, env := range environments {
@@ -76,8 +143,71 @@ var environmentsCmd = &cobra.Command{
 	},
 }
 
+// environmentsListCmd represents the environment list command
+var environmentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the environments of a Business Group",
+	Long:  `List the environments of a Business Group with their ID, type and whether they are production, without prompting for a selection.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		orgID, _ := cmd.Flags().GetString("org")
+		format, _ := cmd.Flags().GetString("output")
+
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON); err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+		if orgID == "" {
+			orgID = client.Org
+		}
+		if orgID == "" {
+			fmt.Println("Please provide a business group ID using the --org flag.")
+			return
+		}
+
+		environments, err := client.GetEnvironments(ctx, orgID)
+		if err != nil {
+			fmt.Printf("Error retrieving environments: %v\n", err)
+			return
+		}
+		results := make([]EnvironmentResult, 0, len(environments))
+		for _, env := range environments {
+			results = append(results, EnvironmentResult{
+				ID:           env.GetId(),
+				Name:         env.GetName(),
+				Type:         env.GetType(),
+				IsProduction: env.GetIsProduction(),
+			})
+		}
+
+		if format == output.JSON {
+			printJSON(os.Stdout, results)
+			return
+		}
+		if len(results) == 0 {
+			fmt.Println("No environments found.")
+			return
+		}
+		printEnvironmentsTable(results)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(environmentsCmd)
+	environmentsCmd.AddCommand(environmentsListCmd)
+	environmentsListCmd.Flags().StringP("org", "o", "", "Business Group ID (defaults to the persisted one)")
+	environmentsListCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
 	environmentsCmd.Flags().StringP("org", "o", "", "Business Group ID")
 	environmentsCmd.MarkFlagRequired("org")
+	environmentsCmd.Flags().String("env-id", "", "ID of the environment to persist, without prompting")
+	environmentsCmd.Flags().String("env-name", "", "Name of the environment to persist, without prompting (case-insensitive)")
+	environmentsCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
 }
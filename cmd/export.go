@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// csvHeader is the header row of the CSV export.
+var csvHeader = []string{"App ID", "Type", "Cluster", "Labels", "Last Called", "Request Count", "Error"}
+
+// writeResultsCSV writes the results as CSV rows. When snapshot is non-zero, every row is
+// prefixed with a "Snapshot Time" column so successive snapshots can be told apart.
+func writeResultsCSV(w io.Writer, results []AppResult, snapshot time.Time, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		row := csvHeader
+		if !snapshot.IsZero() {
+			row = append([]string{"Snapshot Time"}, csvHeader...)
+		}
+		cw.Write(row)
+	}
+	for _, r := range results {
+		cw.Write(csvRow(r, snapshot))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow returns the CSV row of a result, prefixed with the snapshot time when non-zero.
+// The request count of an app whose query failed is left empty rather than reported as 0.
+func csvRow(r AppResult, snapshot time.Time) []string {
+	var lastCalled, requestCount, errMsg string
+	if !r.LastCalled.IsZero() {
+		lastCalled = r.LastCalled.Format(time.RFC3339)
+	}
+	if !slices.Contains(r.FailedMetrics, metricRequestCount) {
+		requestCount = strconv.Itoa(r.RequestCount)
+	}
+	if r.Err != nil {
+		errMsg = r.Err.Error()
+	}
+	row := []string{r.AppID, r.AppType, r.Cluster, formatLabels(r.Labels), lastCalled, requestCount, errMsg}
+	if !snapshot.IsZero() {
+		row = append([]string{snapshot.Format(time.RFC3339)}, row...)
+	}
+	return row
+}
+
+// requestSeriesHeader is the header row of the timeseries output.
+var requestSeriesHeader = []string{"App ID", "Time", "Requests"}
+
+// writeRequestSeriesCSV writes the requests per minute of every app as CSV rows, one
+// row per data point, so that they can be charted.
+func writeRequestSeriesCSV(w io.Writer, results []AppResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(requestSeriesHeader)
+	for _, r := range results {
+		for _, p := range r.Requests {
+			cw.Write([]string{r.AppID, p.Time.Format(time.RFC3339), strconv.FormatFloat(p.Value, 'f', -1, 64)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportResultsToCSV writes the results to the CSV file at path.
+// By default the file is overwritten. In append mode, the results are appended as a
+// snapshot stamped with the current time, building a time series across runs; the
+// header is only written when the file is new or empty.
+func ExportResultsToCSV(path string, results []AppResult, appendMode bool) error {
+	w, err := NewCSVResultWriter(path, appendMode)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := w.Write(r); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// CSVResultWriter writes results to a CSV file one at a time, as they are collected, so
+// that they reach the disk even if the run is interrupted.
+type CSVResultWriter struct {
+	f        *os.File
+	cw       *csv.Writer
+	snapshot time.Time
+	count    int
+}
+
+// NewCSVResultWriter opens the CSV file at path the same way as ExportResultsToCSV and
+// writes its header when needed.
+func NewCSVResultWriter(path string, appendMode bool) (*CSVResultWriter, error) {
+	if !appendMode {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("error creating CSV file: %w", err)
+		}
+		w := &CSVResultWriter{f: f, cw: csv.NewWriter(f)}
+		w.cw.Write(csvHeader)
+		return w, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening CSV file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error opening CSV file: %w", err)
+	}
+	w := &CSVResultWriter{f: f, cw: csv.NewWriter(f), snapshot: time.Now()}
+	if info.Size() == 0 {
+		w.cw.Write(append([]string{"Snapshot Time"}, csvHeader...))
+	}
+	return w, nil
+}
+
+// Write writes the row of a result and flushes it to the file.
+func (w *CSVResultWriter) Write(r AppResult) error {
+	w.cw.Write(csvRow(r, w.snapshot))
+	w.cw.Flush()
+	if err := w.cw.Error(); err != nil {
+		return fmt.Errorf("error writing CSV file: %w", err)
+	}
+	w.count++
+	return nil
+}
+
+// Count returns the number of results written.
+func (w *CSVResultWriter) Count() int {
+	return w.count
+}
+
+// Close flushes the pending rows and closes the file.
+func (w *CSVResultWriter) Close() error {
+	w.cw.Flush()
+	if err := w.cw.Error(); err != nil {
+		w.f.Close()
+		return fmt.Errorf("error writing CSV file: %w", err)
+	}
+	return w.f.Close()
+}
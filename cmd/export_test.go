@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteResultsCSVReportsErrors(t *testing.T) {
+	lastCalled := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	results := []AppResult{
+		{AppID: "orders", AppType: "CloudHub", LastCalled: lastCalled, RequestCount: 42},
+		{AppID: "billing", AppType: "CloudHub", FailedMetrics: []string{metricLastCalled, metricRequestCount},
+			Err: errors.New("request-count: timeout")},
+		{AppID: "stock", AppType: "CloudHub", RequestCount: 7, FailedMetrics: []string{metricLastCalled},
+			Err: errors.New("last-called: timeout")},
+	}
+
+	var b strings.Builder
+	if err := writeResultsCSV(&b, results, time.Time{}, true); err != nil {
+		t.Fatalf("writeResultsCSV: %v", err)
+	}
+	want := `App ID,Type,Cluster,Labels,Last Called,Request Count,Error
+orders,CloudHub,,-,2024-05-01T12:00:00Z,42,
+billing,CloudHub,,-,,,request-count: timeout
+stock,CloudHub,,-,,7,last-called: timeout
+`
+	if got := b.String(); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
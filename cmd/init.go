@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mulesoft-anypoint/anypoint-client-go/org"
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// prompt asks for a value on stdin, returning def when the answer is empty.
+func prompt(reader *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	if input = strings.TrimSpace(input); input == "" {
+		return def, nil
+	}
+	return input, nil
+}
+
+// promptSecret asks for a secret on stdin without echoing it when stdin is a terminal.
+func promptSecret(reader *bufio.Reader, label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(reader, label, "")
+	}
+	fmt.Printf("%s: ", label)
+	secret, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// promptSelection asks for the number of one of n listed choices, 1 by default, and
+// returns its index.
+func promptSelection(reader *bufio.Reader, label string, n int) (int, error) {
+	input, err := prompt(reader, label, "1")
+	if err != nil {
+		return 0, err
+	}
+	selection, err := strconv.Atoi(input)
+	if err != nil || selection < 1 || selection > n {
+		return 0, fmt.Errorf("invalid selection %q", input)
+	}
+	return selection - 1, nil
+}
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:     "init",
+	Aliases: []string{"setup"},
+	Short:   "Set up MuleTracker in a guided flow",
+	Long: `Set up MuleTracker in one guided flow: prompt for the control plane and the connected app
+credentials, connect, then select the business group and the environment to persist, as
+'connect', 'business-groups use' and 'environment' would.
+
+Every value given by flag is not prompted for, so the setup can be scripted, e.g.:
+  muletracker-cli init -c eu -i CLIENT_ID -s CLIENT_SECRET --org ORG_ID --env Sandbox
+
+The persisted values are offered as defaults. The client secret is not echoed when typed.
+A business group given by name is looked up in the tree of the root business group,
+which is prompted for when no business group is persisted yet.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		controlPlane, _ := cmd.Flags().GetString("controlplane")
+		clientId, _ := cmd.Flags().GetString("clientId")
+		clientSecret, _ := cmd.Flags().GetString("clientSecret")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		orgArg, _ := cmd.Flags().GetString("org")
+		envArg, _ := cmd.Flags().GetString("env")
+		reader := bufio.NewReader(os.Stdin)
+
+		var err error
+		if controlPlane == "" && baseURL == "" {
+			def := viper.GetString(config.Key("controlplane"))
+			if def == "" {
+				def = "us"
+			}
+			if controlPlane, err = prompt(reader, "Control plane (us, eu, gov)", def); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if controlPlane == "" {
+			controlPlane = "us"
+		}
+		serverIndex := anypoint.ServerIndex(strings.ToLower(controlPlane))
+		if serverIndex == -1 {
+			fmt.Println("Invalid control plane. Valid values are 'eu', 'us', or 'gov'.")
+			return
+		}
+		if baseURL != "" {
+			if err := anypoint.ValidateBaseURL(baseURL); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if clientId == "" {
+			if clientId, err = prompt(reader, "Connected app client ID", viper.GetString(config.Key("clientId"))); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if clientSecret == "" {
+			persisted := config.GetSecret(config.Key("clientSecret"))
+			label := "Connected app client secret"
+			if persisted != "" {
+				label += " (empty keeps the persisted one)"
+			}
+			if clientSecret, err = promptSecret(reader, label); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if clientSecret == "" {
+				clientSecret = persisted
+			}
+		}
+		if clientId == "" || clientSecret == "" {
+			fmt.Println("clientId and clientSecret are required.")
+			return
+		}
+
+		// Connect as the 'connect' command does.
+		viper.Set(config.Key("controlplane"), strings.ToLower(controlPlane))
+		viper.Set(config.Key("baseUrl"), baseURL)
+		client, err := anypoint.NewClient(ctx, serverIndex, clientId, clientSecret)
+		if err != nil {
+			fmt.Printf("Error connecting to Anypoint: %v\n", err)
+			return
+		}
+		fmt.Printf("Successfully connected. Access token valid until %s.\n", client.ExpiresAt.Format(time.RFC1123))
+
+		// Select the business group, in the tree of the root one. A name is resolved
+		// against the persisted org, or against the root given at the prompt on first setup.
+		var orgID string
+		if orgArg != "" && (guidPattern.MatchString(orgArg) || client.Org != "") {
+			if orgID, _, err = resolveOrgEnv(ctx, client, orgArg, ""); err != nil {
+				fmt.Println(err)
+				return
+			}
+		} else {
+			root, err := prompt(reader, "Root business group ID", client.Org)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if root == "" {
+				fmt.Println("A business group ID is required.")
+				return
+			}
+			nodes, err := client.GetBusinessGroupTree(ctx, root)
+			if err != nil {
+				fmt.Printf("Error retrieving business groups: %v\n", err)
+				return
+			}
+			if orgArg != "" {
+				if orgID, err = findBusinessGroupByName(nodes, orgArg); err != nil {
+					fmt.Println(err)
+					return
+				}
+			} else {
+				fmt.Println("Business groups:")
+				for idx, node := range nodes {
+					fmt.Printf("%d) %s (ID: %s)\n", idx+1, node.Path, node.ID)
+				}
+				selection, err := promptSelection(reader, "Select business group number to use", len(nodes))
+				if err != nil {
+					fmt.Println(err)
+					return
+				}
+				orgID = nodes[selection].ID
+			}
+		}
+		if client.Org != orgID {
+			client.Env = ""
+		}
+		client.SetOrg(orgID)
+
+		// Select the environment of the business group.
+		environments, err := client.GetEnvironments(ctx, orgID)
+		if err != nil {
+			fmt.Printf("Error retrieving environments: %v\n", err)
+			return
+		}
+		if len(environments) == 0 {
+			fmt.Println("No environments found.")
+			return
+		}
+		var env org.Environment
+		if envArg != "" {
+			if guidPattern.MatchString(envArg) {
+				env, err = findEnvironment(environments, envArg, "")
+			} else {
+				env, err = findEnvironment(environments, "", envArg)
+			}
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+		} else {
+			fmt.Println("Environments:")
+			for idx, env := range environments {
+				fmt.Printf("%d) %s (ID: %s)\n", idx+1, env.GetName(), env.GetId())
+			}
+			selection, err := promptSelection(reader, "Select environment number to use", len(environments))
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			env = environments[selection]
+		}
+		client.SetEnv(env.GetId())
+
+		PrintClientInfo(client)
+		fmt.Printf("Setup complete. Run 'monitor' to monitor the apps of %s.\n", env.GetName())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringP("controlplane", "c", "", "Control plane to use (eu, us, gov)")
+	initCmd.Flags().StringP("clientId", "i", "", "Anypoint Platform connected app client id")
+	initCmd.Flags().StringP("clientSecret", "s", "", "Anypoint Platform connected app client secret")
+	initCmd.Flags().String("base-url", "", "Base URL of a private or dedicated control plane, e.g. https://anypoint.example.com (takes precedence over --controlplane)")
+	initCmd.Flags().String("org", "", "Business group to persist, by ID or name (a name is looked up in the tree of the root business group)")
+	initCmd.Flags().String("env", "", "Environment to persist, by ID or name")
+	initCmd.RegisterFlagCompletionFunc("controlplane", completeControlPlane)
+}
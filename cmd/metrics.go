@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"gopkg.in/yaml.v3"
+)
+
+// metricsFile is the schema of the file passed to --metrics-file.
+type metricsFile struct {
+	Metrics []anypoint.CustomMetric `yaml:"metrics"`
+}
+
+// MetricValue is the value of a custom or resource usage metric for an app.
+type MetricValue struct {
+	Name    string
+	Value   float64
+	HasData bool
+}
+
+// LoadMetricsFile reads and validates the custom metric definitions of a metrics file.
+// Unknown keys, invalid definitions and duplicate names are rejected.
+func LoadMetricsFile(path string) ([]anypoint.CustomMetric, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening metrics file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	var file metricsFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("error parsing metrics file: %w", err)
+	}
+	if len(file.Metrics) == 0 {
+		return nil, fmt.Errorf("metrics file %s defines no metric", path)
+	}
+
+	seen := make(map[string]bool, len(file.Metrics))
+	for _, m := range file.Metrics {
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid metrics file: %w", err)
+		}
+		if seen[m.Name] {
+			return nil, fmt.Errorf("invalid metrics file: metric %s is defined twice", m.Name)
+		}
+		seen[m.Name] = true
+	}
+	return file.Metrics, nil
+}
+
+// collectCustomMetrics computes the custom metrics of every result, with the same
+// concurrency and rate (requests per second) as the monitoring queries. Failed queries are reported
+// and leave the metric without data.
+func collectCustomMetrics(ctx context.Context, client *anypoint.Client, orgID, envID string, metrics []anypoint.CustomMetric, results []AppResult, concurrency int, rate float64) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	rateLimiter := newRateLimiter(rate)
+	defer rateLimiter.Stop()
+
+	for i := range results {
+		results[i].Metrics = make([]MetricValue, len(metrics))
+		wg.Add(1)
+		go func(r *AppResult) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}: // Acquire semaphore.
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }() // Release semaphore.
+			for j, m := range metrics {
+				select {
+				case <-rateLimiter.C: // Wait for rate limiter tick.
+				case <-ctx.Done():
+					return
+				}
+				value, ok, err := client.GetCustomMetric(ctx, orgID, envID, r.app, m)
+				if err != nil {
+					anypoint.Logger.Error("error monitoring app", "app", r.AppID, "metric", m.Name, "err", err)
+				}
+				r.Metrics[j] = MetricValue{Name: m.Name, Value: value, HasData: ok}
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+}
+
+// formatMetricValue renders a custom metric value, or "-" when there is no data.
+func formatMetricValue(v MetricValue) string {
+	if !v.HasData {
+		return "-"
+	}
+	return strconv.FormatFloat(v.Value, 'f', -1, 64)
+}
+
+// builtinMetrics lists the valid values of the --metrics flag. The requests and
+// last-called time are always collected; cpu and memory add resource usage columns.
+var builtinMetrics = []string{"requests", "lastcalled", "cpu", "memory"}
+
+// resourceHeaders maps the resource usage metrics to their column header.
+var resourceHeaders = map[string]string{
+	"cpu":    "CPU %",
+	"memory": "Memory MB",
+}
+
+// parseMetricsFlag validates the --metrics values and returns the resource usage
+// metrics to collect, in the order they were given.
+func parseMetricsFlag(names []string) ([]string, error) {
+	var resources []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(builtinMetrics, name) {
+			return nil, fmt.Errorf("invalid metric %q. Valid values are %s", name, strings.Join(builtinMetrics, ", "))
+		}
+		if _, ok := resourceHeaders[name]; ok && !slices.Contains(resources, name) {
+			resources = append(resources, name)
+		}
+	}
+	return resources, nil
+}
+
+// collectResourceUsage computes the selected resource usage metrics (cpu, memory) of every
+// result over its request-count window, with the same concurrency and rate as the
+// monitoring queries. The CPU is a percentage and the memory the heap used in MB.
+func collectResourceUsage(ctx context.Context, client *anypoint.Client, orgID, envID string, resources []string, results []AppResult, concurrency int, rate float64) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	rateLimiter := newRateLimiter(rate)
+	defer rateLimiter.Stop()
+
+	for i := range results {
+		results[i].Resources = make([]MetricValue, len(resources))
+		wg.Add(1)
+		go func(r *AppResult) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}: // Acquire semaphore.
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }() // Release semaphore.
+			for j, name := range resources {
+				select {
+				case <-rateLimiter.C: // Wait for rate limiter tick.
+				case <-ctx.Done():
+					return
+				}
+				var value float64
+				var ok bool
+				var err error
+				switch name {
+				case "cpu":
+					value, ok, err = client.GetCpuUsage(ctx, orgID, envID, r.app, r.RCWindow)
+				case "memory":
+					value, ok, err = client.GetMemoryUsage(ctx, orgID, envID, r.app, r.RCWindow)
+					value /= 1024 * 1024
+				}
+				if err != nil {
+					anypoint.Logger.Error("error monitoring app", "app", r.AppID, "metric", name, "err", err)
+				}
+				r.Resources[j] = MetricValue{Name: name, Value: math.Round(value*10) / 10, HasData: ok}
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+}
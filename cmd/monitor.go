@@ -1,103 +1,576 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/tabwriter"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/notify"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
 type AppResult struct {
-	AppID        string
-	AppType      string
-	LastCalled   time.Time
-	RequestCount int
-	Err          error
-	LCWindow     string // Last Called window used in the query
-	RCWindow     string // Request Count window used in the query
+	AppID         string
+	AppType       string
+	Cluster       string // RTF cluster (fabric) name, empty for other targets
+	Labels        map[string]string
+	LastCalled    time.Time
+	RequestCount  int
+	Requests      []anypoint.DataPoint // requests per minute over the request-count window
+	Err           error
+	FailedMetrics []string      // metrics whose query still failed after all attempts (metricLastCalled, metricRequestCount)
+	LCWindow      string        // Last Called window used in the query
+	RCWindow      string        // Request Count window used in the query
+	QueryTime     time.Duration // time spent querying InfluxDB, excluding rate limiting
+	Metrics       []MetricValue // custom metrics, in the order of the metrics file
+	Resources     []MetricValue // resource usage metrics selected with --metrics (cpu, memory)
+
+	app anypoint.App // the monitored app, kept so failed queries can be retried
 }
 
+const (
+	// defaultConcurrency is the number of apps monitored in parallel by default.
+	defaultConcurrency = 5
+	// defaultRate is the maximum number of monitoring requests per second by default.
+	defaultRate = 10.0
+	// retryConcurrencyLimit is the lower parallelism used when retrying failed apps.
+	retryConcurrencyLimit = 1
+)
+
+// lastMonitorKey is the configuration key holding the flags of the last saved monitor invocation.
+const lastMonitorKey = "lastMonitor"
+
 var includeEmpty bool
 
+// relativeTime renders the last-called times of the table outputs relative to now (see --relative-time).
+var relativeTime bool
+
+// showTotals adds a footer row with the app, request and idle totals to the summary table (see --totals).
+var showTotals bool
+
+// showRuntime adds a Runtime column with the Mule version and its support status to the summary table (see --show-runtime).
+var showRuntime bool
+
 // ----- Helper Functions ----- //
 
 // getAppsToMonitor retrieves the list of apps based on the provided flags.
-// If a specific appName is provided, it returns a slice with that single app.
+// If a single app is given, it returns the apps matching it, to be resolved with resolveSingleApp.
+// If several apps are given, it returns exactly one app for each of them.
 // Otherwise, it calls the GetApps method on the client.
-func getAppsToMonitor(ctx context.Context, client *anypoint.Client, orgID, envID, appID string, filters ...anypoint.AppFilter) ([]anypoint.App, error) {
-	apps, err := client.GetApps(ctx, orgID, envID, filters...)
+// Apps deployed to unsupported targets are reported once; they fail the run when strictTypes is set.
+func getAppsToMonitor(ctx context.Context, client *anypoint.Client, orgID, envID string, appIDs []string, strictTypes bool, filters ...anypoint.AppFilter) ([]anypoint.App, error) {
+	apps, err := client.GetApps(ctx, orgID, envID)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving apps: %v", err)
 	}
+	if err := checkUnknownTargetTypes(apps, strictTypes); err != nil {
+		return nil, err
+	}
+	apps = anypoint.FilterApps(apps, filters...)
+
+	// The given apps are selected from the full list, so that they carry the domain and
+	// target the monitoring queries need.
+	switch len(appIDs) {
+	case 0:
+		return apps, nil
+	case 1:
+		return matchApps(apps, appIDs[0]), nil
+	}
+	selected := make([]anypoint.App, 0, len(appIDs))
+	seen := make(map[string]bool, len(appIDs))
+	for _, appID := range appIDs {
+		matches := matchApps(apps, appID)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("app %s not found", appID)
+		}
+		app, err := resolveSingleApp(matches, appID)
+		if err != nil {
+			return nil, err
+		}
+		// The same app may be given both by name and by ID.
+		if key := app.GetType() + "/" + app.ID; !seen[key] {
+			seen[key] = true
+			selected = append(selected, app)
+		}
+	}
+	return selected, nil
+}
+
+// matchApps returns the apps matching an app given by name or, failing that, by ID.
+func matchApps(apps []anypoint.App, appID string) []anypoint.App {
+	if matches := anypoint.FilterApps(apps, anypoint.FilterByName(appID)); len(matches) > 0 {
+		return matches
+	}
+	return anypoint.FilterApps(apps, anypoint.FilterByID(appID))
+}
+
+// appStatuses lists the valid values of the --app-status flag.
+var appStatuses = []string{"running", "stopped", "undeployed", "all"}
+
+// appStatusFilters returns the filters selecting the apps of the given --app-status.
+func appStatusFilters(status string) []anypoint.AppFilter {
+	switch strings.ToLower(status) {
+	case "running":
+		return []anypoint.AppFilter{anypoint.FilterRunning}
+	case "stopped":
+		return []anypoint.AppFilter{anypoint.FilterStopped}
+	case "undeployed":
+		return []anypoint.AppFilter{anypoint.FilterUndeployed}
+	}
+	return nil
+}
+
+// appTypeFilters returns the filters selecting the apps of the given --app-type.
+func appTypeFilters(appType string) []anypoint.AppFilter {
+	var filters []anypoint.AppFilter
+	switch strings.ToLower(appType) {
+	case "cloudhub":
+		filters = append(filters, anypoint.FilterCH1)
+	case "cloudhub2":
+		filters = append(filters, anypoint.FilterCH2)
+	case "rtf":
+		filters = append(filters, anypoint.FilterRTF)
+	case "all":
+		filters = append(filters, anypoint.FilterMonitorable)
+	}
+	return filters
+}
+
+// resolveSingleApp returns the only app matching name. The same artifact name may be
+// deployed to both CloudHub and RTF, in which case the caller must disambiguate by type.
+func resolveSingleApp(apps []anypoint.App, name string) (anypoint.App, error) {
+	if len(apps) == 1 {
+		return apps[0], nil
+	}
+	types := make([]string, 0, len(apps))
+	for _, app := range apps {
+		types = append(types, app.GetType())
+	}
+	return anypoint.App{}, fmt.Errorf("%d apps are named %s (%s); use --app-type cloudhub, cloudhub2 or rtf to pick one",
+		len(apps), name, strings.Join(types, ", "))
+}
+
+// idleReason returns why the app of a result is considered idle, or "" if it is active.
+// An app is idle when it received no request over the request-count window or, when
+// threshold is positive, when it was last called more than threshold before now.
+func idleReason(res AppResult, threshold time.Duration, now time.Time) string {
+	if res.RequestCount == 0 {
+		return fmt.Sprintf("no requests %s", overWindow(res.RCWindow))
+	}
+	if threshold > 0 {
+		if res.LastCalled.IsZero() {
+			return fmt.Sprintf("not called %s", overWindow(res.LCWindow))
+		}
+		if idle := now.Sub(res.LastCalled); idle > threshold {
+			return fmt.Sprintf("last called %s ago, more than %s", idle.Round(time.Second), threshold)
+		}
+	}
+	return ""
+}
+
+// rangeWindow returns the window label of an absolute time range, in the ISO 8601
+// interval notation since/until. An open-ended range ends "now".
+func rangeWindow(since, until time.Time) string {
+	end := "now"
+	if !until.IsZero() {
+		end = until.Format(time.RFC3339)
+	}
+	return since.Format(time.RFC3339) + "/" + end
+}
+
+// parseRangeFlag parses the RFC3339 timestamp of the --since or --until flag.
+// An empty value yields the zero time.
+func parseRangeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: expected an RFC3339 timestamp such as 2024-05-02T14:00:00Z", name, value)
+	}
+	return t, nil
+}
+
+// overWindow describes the period a window label covers, e.g. "over the last 24h".
+func overWindow(window string) string {
+	if since, until, ok := strings.Cut(window, "/"); ok {
+		return fmt.Sprintf("between %s and %s", since, until)
+	}
+	return "over the last " + window
+}
+
+// exitAppsFailed is the exit status of a run in which some apps could not be monitored.
+const exitAppsFailed = 2
+
+// exitIfAppsFailed exits with exitAppsFailed when some of the results carry an error.
+func exitIfAppsFailed(results []AppResult) {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d apps could not be monitored (%s).\n", failed, len(results), describeFailures(results))
+		os.Exit(exitAppsFailed)
+	}
+}
+
+// describeFailures counts the failed apps per metric, e.g. "3 failed request-count, 1 failed last-called".
+func describeFailures(results []AppResult) string {
+	counts := make(map[string]int)
+	for _, r := range results {
+		for _, m := range r.FailedMetrics {
+			counts[m]++
+		}
+	}
+	var parts []string
+	for _, m := range []string{metricRequestCount, metricLastCalled} {
+		if counts[m] > 0 {
+			parts = append(parts, fmt.Sprintf("%d failed %s", counts[m], m))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// exitIfGated exits with a non-zero status when gated is set, so that the
+// --fail-if-idle gate also fails when the app cannot be monitored at all.
+func exitIfGated(gated bool) {
+	if gated {
+		os.Exit(1)
+	}
+}
 
-	if appID != "" {
-		return anypoint.FilterApps(apps, anypoint.FilterByName(appID)), nil
+// checkUnknownTargetTypes reports the deployment targets of apps that cannot be monitored.
+// It prints a single warning listing them, or returns an error when strict is set.
+func checkUnknownTargetTypes(apps []anypoint.App, strict bool) error {
+	unknown := anypoint.UnknownTargetTypes(apps)
+	if len(unknown) == 0 {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("apps deployed to unsupported targets: %s", strings.Join(unknown, ", "))
 	}
-	// Otherwise, retrieve all apps.
-	return apps, nil
+	anypoint.Logger.Warn("some apps are deployed to targets that cannot be monitored yet and are skipped", "targets", strings.Join(unknown, ", "))
+	return nil
 }
 
+// The metrics of the monitoring queries, as reported when they fail.
+const (
+	metricLastCalled   = "last-called"
+	metricRequestCount = "request-count"
+)
+
 // monitorSingleApp retrieves monitoring data for a single app.
 func monitorSingleApp(ctx context.Context, client *anypoint.Client, orgID, envID string, app anypoint.App, lcWindow, rcWindow string) AppResult {
 	var res AppResult
 	// res.AppID = app.ID
 	res.AppID = app.Artifact.Name
 	res.AppType = app.GetType()
+	res.Labels = app.Labels
 	res.LCWindow = lcWindow
 	res.RCWindow = rcWindow
+	res.app = app
 
+	// Failing queries are retried by the client, up to its MaxAttempts.
+	start := time.Now()
 	lastCalled, err1 := client.GetLastCalledTime(ctx, orgID, envID, app, lcWindow)
-	reqCount, err2 := client.GetRequestCount(ctx, orgID, envID, app, rcWindow)
-	if err1 != nil || err2 != nil {
-		res.Err = fmt.Errorf("lastCalled error: %v, requestCount error: %v", err1, err2)
+	requests, err2 := client.GetRequestSeries(ctx, orgID, envID, app, rcWindow)
+	res.QueryTime = time.Since(start)
+	// Keep the error on one line, it is shown in the summary table.
+	switch {
+	case err1 != nil && err2 != nil:
+		res.FailedMetrics = []string{metricLastCalled, metricRequestCount}
+		res.Err = fmt.Errorf("%s: %w; %s: %w", metricLastCalled, err1, metricRequestCount, err2)
+	case err1 != nil:
+		res.FailedMetrics = []string{metricLastCalled}
+		res.Err = fmt.Errorf("%s: %w", metricLastCalled, err1)
+	case err2 != nil:
+		res.FailedMetrics = []string{metricRequestCount}
+		res.Err = fmt.Errorf("%s: %w", metricRequestCount, err2)
 	}
 	res.LastCalled = lastCalled
-	res.RequestCount = reqCount
+	res.Requests = requests
+	res.RequestCount = anypoint.TotalRequests(requests)
 	return res
 }
 
-// monitorAppsConcurrently monitors a list of apps with concurrency and rate limiting.
-func monitorAppsConcurrently(ctx context.Context, client *anypoint.Client, orgID, envID, lcWindow, rcWindow string, apps []anypoint.App) []AppResult {
-	const concurrencyLimit = 5
-	sem := make(chan struct{}, concurrencyLimit)
+// newRateLimiter returns a ticker allowing rate requests per second.
+func newRateLimiter(rate float64) *time.Ticker {
+	return time.NewTicker(time.Duration(float64(time.Second) / rate))
+}
+
+// monitorOptions holds the settings of a monitor run, read once from its flags.
+type monitorOptions struct {
+	orgID            string
+	envID            string
+	appIDs           []string
+	lcWindow         string
+	rcWindow         string
+	sinceFlag        string
+	untilFlag        string
+	since            time.Time // parsed from sinceFlag, replaces the windows when set
+	until            time.Time // parsed from untilFlag
+	timezone         string
+	dataFilter       string
+	appType          string
+	appStatus        string
+	appName          string
+	appNameRegex     string
+	labelSelectors   []string
+	minRequests      int
+	maxRequests      int
+	sortKey          string
+	sortDesc         bool
+	strictTypes      bool
+	limit            int
+	maxAttempts      int
+	retryFailed      bool
+	concurrency      int
+	rate             float64
+	measureLatency   bool
+	showErrors       bool
+	metricNames      []string
+	resources        []string // parsed from metricNames
+	metricsFile      string
+	metrics          []anypoint.CustomMetric // loaded from metricsFile
+	format           string
+	formatTemplate   string
+	outputFile       string
+	influxWriteURL   string
+	influxWriteToken string
+	csvPath          string
+	csvAppend        bool
+	failIfIdle       bool
+	idleThreshold    time.Duration
+	slackWebhook     string
+	watch            bool
+	watchInterval    time.Duration
+	printConfig      bool
+	saveLast         bool
+}
+
+// newMonitorOptions reads the settings of a monitor run from its flags. The values
+// derived from them, such as the time range and the metrics, are filled in by the caller
+// once validated.
+func newMonitorOptions(flags *pflag.FlagSet) monitorOptions {
+	var o monitorOptions
+	o.orgID, _ = flags.GetString("org")
+	o.envID, _ = flags.GetString("env")
+	o.appIDs, _ = flags.GetStringSlice("app")
+	o.lcWindow, _ = flags.GetString("last-called-window")
+	o.rcWindow, _ = flags.GetString("request-count-window")
+	o.sinceFlag, _ = flags.GetString("since")
+	o.untilFlag, _ = flags.GetString("until")
+	o.timezone, _ = flags.GetString("timezone")
+	o.dataFilter, _ = flags.GetString("filter")
+	o.appType, _ = flags.GetString("app-type")
+	o.appStatus, _ = flags.GetString("app-status")
+	o.appName, _ = flags.GetString("app-name")
+	o.appNameRegex, _ = flags.GetString("app-name-regex")
+	o.labelSelectors, _ = flags.GetStringSlice("label")
+	o.minRequests, _ = flags.GetInt("min-requests")
+	o.maxRequests, _ = flags.GetInt("max-requests")
+	o.sortKey, _ = flags.GetString("sort")
+	o.sortDesc, _ = flags.GetBool("sort-desc")
+	o.strictTypes, _ = flags.GetBool("strict-types")
+	o.limit, _ = flags.GetInt("limit")
+	o.maxAttempts, _ = flags.GetInt("max-attempts")
+	o.retryFailed, _ = flags.GetBool("retry-failed")
+	o.concurrency, _ = flags.GetInt("concurrency")
+	o.rate, _ = flags.GetFloat64("rate")
+	o.measureLatency, _ = flags.GetBool("measure-latency")
+	o.showErrors, _ = flags.GetBool("show-errors")
+	o.metricNames, _ = flags.GetStringSlice("metrics")
+	o.metricsFile, _ = flags.GetString("metrics-file")
+	o.format, _ = flags.GetString("output")
+	o.formatTemplate, _ = flags.GetString("format")
+	o.outputFile, _ = flags.GetString("output-file")
+	o.influxWriteURL, _ = flags.GetString("influx-write-url")
+	o.influxWriteToken, _ = flags.GetString("influx-write-token")
+	o.csvPath, _ = flags.GetString("csv")
+	o.csvAppend, _ = flags.GetBool("csv-append")
+	o.failIfIdle, _ = flags.GetBool("fail-if-idle")
+	o.idleThreshold, _ = flags.GetDuration("idle-threshold")
+	o.slackWebhook, _ = flags.GetString("slack-webhook")
+	o.watch, _ = flags.GetBool("watch")
+	o.watchInterval, _ = flags.GetDuration("watch-interval")
+	o.printConfig, _ = flags.GetBool("print-config")
+	o.saveLast, _ = flags.GetBool("save-last")
+	return o
+}
+
+// monitorAppsConcurrently monitors a list of apps in the org, env and windows of opts, with
+// at most opts.concurrency apps in parallel and opts.rate requests per second. When limit is
+// positive, monitoring stops as soon as limit results are collected: the queries still in
+// flight are cancelled and their results discarded.
+// When onResult is not nil, it is called with every kept result as soon as it is collected.
+func monitorAppsConcurrently(ctx context.Context, client *anypoint.Client, opts monitorOptions, apps []anypoint.App, limit int, onResult func(AppResult)) []AppResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, opts.concurrency)
 	var wg sync.WaitGroup
 	resultsCh := make(chan AppResult, len(apps))
 
-	// Create a rate limiter ticker: 10 requests per second.
-	rateLimiter := time.NewTicker(100 * time.Millisecond)
+	rateLimiter := newRateLimiter(opts.rate)
 	defer rateLimiter.Stop()
 
 	for _, app := range apps {
 		wg.Add(1)
 		go func(app anypoint.App) {
 			defer wg.Done()
-			sem <- struct{}{}        // Acquire semaphore.
+			select {
+			case sem <- struct{}{}: // Acquire semaphore.
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-sem }() // Release semaphore.
-			<-rateLimiter.C          // Wait for rate limiter tick.
-			result := monitorSingleApp(ctx, client, orgID, envID, app, lcWindow, rcWindow)
+			select {
+			case <-rateLimiter.C: // Wait for rate limiter tick.
+			case <-ctx.Done():
+				return
+			}
+			result := monitorSingleApp(ctx, client, opts.orgID, opts.envID, app, opts.lcWindow, opts.rcWindow)
 			resultsCh <- result
 		}(app)
 	}
 
-	wg.Wait()
-	close(resultsCh)
+	go func() {
+		wg.Wait()
+		close(resultsCh)
+	}()
 
 	var results []AppResult
 	for r := range resultsCh {
+		// Discard the results of the queries cancelled once the limit was reached.
+		if limit > 0 && len(results) >= limit {
+			continue
+		}
+		// Discard the results of the queries cancelled by an interruption.
+		if r.Err != nil && ctx.Err() != nil {
+			continue
+		}
 		if r.Err != nil {
-			fmt.Fprintf(os.Stderr, "Error monitoring app %s: %v\n", r.AppID, r.Err)
+			anypoint.Logger.Error("error monitoring app", "app", r.AppID, "err", r.Err)
 		}
 		results = append(results, r)
+		if onResult != nil {
+			onResult(r)
+		}
+		if limit > 0 && len(results) >= limit {
+			cancel()
+		}
 	}
 	return results
 }
 
+// retryFailedResults re-monitors the apps whose results carry an error, using a lower
+// concurrency, and merges the successful retries into results.
+// It returns the merged results along with the number of attempted and recovered apps.
+func retryFailedResults(ctx context.Context, client *anypoint.Client, opts monitorOptions, results []AppResult) ([]AppResult, int, int) {
+	var failed []anypoint.App
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r.app)
+		}
+	}
+	if len(failed) == 0 {
+		return results, 0, 0
+	}
+
+	recovered := make(map[string]AppResult)
+	opts.concurrency = retryConcurrencyLimit
+	for _, r := range monitorAppsConcurrently(ctx, client, opts, failed, 0, nil) {
+		if r.Err == nil {
+			recovered[r.app.ID] = r
+		}
+	}
+
+	merged := make([]AppResult, 0, len(results))
+	for _, r := range results {
+		if retried, ok := recovered[r.app.ID]; ok && r.Err != nil {
+			r = retried
+		}
+		merged = append(merged, r)
+	}
+	return merged, len(failed), len(recovered)
+}
+
+// resolveClusterNames sets the Cluster of every RTF result to its fabric name.
+// The fabric list is fetched at most once per run; fabrics that cannot be resolved
+// are shown by their ID.
+func resolveClusterNames(ctx context.Context, client *anypoint.Client, orgID string, results []AppResult) {
+	var names map[string]string
+	for i := range results {
+		app := results[i].app
+		if !anypoint.FilterRTF(app) {
+			continue
+		}
+		if names == nil {
+			names = fabricNames(ctx, client, orgID)
+		}
+		if name := names[app.Target.ID]; name != "" {
+			results[i].Cluster = name
+		} else {
+			results[i].Cluster = app.Target.ID
+		}
+	}
+}
+
+// fabricNames returns the RTF fabric names by ID, or an empty map if they cannot be retrieved.
+func fabricNames(ctx context.Context, client *anypoint.Client, orgID string) map[string]string {
+	names, err := client.GetFabricNames(ctx, orgID)
+	if err != nil {
+		anypoint.Logger.Warn("unable to resolve RTF cluster names", "err", err)
+		return map[string]string{}
+	}
+	return names
+}
+
+// newCSVStream returns a callback writing the results that pass the filter and request
+// thresholds to w as they are collected, with their cluster names resolved.
+// The fabric names are fetched once, with the first RTF result.
+func newCSVStream(ctx context.Context, client *anypoint.Client, orgID string, w *CSVResultWriter, dataFilter string, minRequests, maxRequests int) func(AppResult) {
+	var names map[string]string
+	return func(r AppResult) {
+		if len(filterRequestThresholds(filterAppResults([]AppResult{r}, dataFilter), minRequests, maxRequests)) == 0 {
+			return
+		}
+		if anypoint.FilterRTF(r.app) {
+			if names == nil {
+				names = fabricNames(ctx, client, orgID)
+			}
+			r.Cluster = r.app.Target.ID
+			if name := names[r.app.Target.ID]; name != "" {
+				r.Cluster = name
+			}
+		}
+		if err := w.Write(r); err != nil {
+			anypoint.Logger.Error("error streaming result to CSV", "app", r.AppID, "err", err)
+		}
+	}
+}
+
 // filterAppResults applies the filter flag to the full list of results.
 // filterFlag can be: "all", "nonempty", or "empty".
 func filterAppResults(results []AppResult, filterFlag string) []AppResult {
@@ -122,41 +595,383 @@ func filterAppResults(results []AppResult, filterFlag string) []AppResult {
 	return filtered
 }
 
+// idleApps returns the apps that received no requests, leaving out the ones that could
+// not be monitored.
+func idleApps(results []AppResult) []notify.IdleApp {
+	var idle []notify.IdleApp
+	for _, r := range filterAppResults(results, "empty") {
+		if r.Err == nil {
+			idle = append(idle, notify.IdleApp{ID: r.AppID, Type: r.AppType, LastCalled: r.LastCalled})
+		}
+	}
+	return idle
+}
+
+// filterRequestThresholds keeps the results with at least minRequests requests and, unless
+// maxRequests is negative, at most maxRequests.
+func filterRequestThresholds(results []AppResult, minRequests, maxRequests int) []AppResult {
+	var filtered []AppResult
+	for _, r := range results {
+		if r.RequestCount >= minRequests && (maxRequests < 0 || r.RequestCount <= maxRequests) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// describeThresholds describes the active request thresholds, e.g. "min 10, max 100000".
+func describeThresholds(minRequests, maxRequests int) string {
+	var parts []string
+	if minRequests > 0 {
+		parts = append(parts, fmt.Sprintf("min %d", minRequests))
+	}
+	if maxRequests >= 0 {
+		parts = append(parts, fmt.Sprintf("max %d", maxRequests))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// watchApps monitors the apps on every opts.watchInterval, clearing the screen and
+// re-printing the summary table under a timestamp header, until ctx is cancelled, e.g. by
+// Ctrl-C. Each run goes through monitorAppsConcurrently, with its concurrency and rate limits.
+// With opts.csvPath, the results of each run are appended to the CSV file as a snapshot; the
+// file is first emptied unless opts.csvAppend is set.
+func watchApps(ctx context.Context, client *anypoint.Client, opts monitorOptions, apps []anypoint.App) {
+	if opts.csvPath != "" && !opts.csvAppend {
+		if err := os.Remove(opts.csvPath); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error exporting results to CSV: %v\n", err)
+			return
+		}
+	}
+	ticker := time.NewTicker(opts.watchInterval)
+	defer ticker.Stop()
+	for {
+		results := monitorAppsConcurrently(ctx, client, opts, apps, 0, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		resolveClusterNames(ctx, client, opts.orgID, results)
+		if len(opts.resources) > 0 {
+			collectResourceUsage(ctx, client, opts.orgID, opts.envID, opts.resources, results, opts.concurrency, opts.rate)
+		}
+		if len(opts.metrics) > 0 {
+			collectCustomMetrics(ctx, client, opts.orgID, opts.envID, opts.metrics, results, opts.concurrency, opts.rate)
+		}
+		results = filterRequestThresholds(filterAppResults(results, opts.dataFilter), opts.minRequests, opts.maxRequests)
+		if opts.sortKey != "" {
+			sortAppResults(results, opts.sortKey, opts.sortDesc)
+		}
+
+		fmt.Print(clearScreen)
+		fmt.Printf("Every %s, %d apps (last-called window %s, request count window %s). Press Ctrl-C to stop.\n", opts.watchInterval, len(apps), opts.lcWindow, opts.rcWindow)
+		fmt.Printf("Updated at %s\n", time.Now().Format(time.RFC1123))
+		if len(results) == 0 {
+			fmt.Println("\nNo apps match the filter criteria.")
+		} else {
+			printSummary(results, opts)
+		}
+		if opts.csvPath != "" {
+			if err := ExportResultsToCSV(opts.csvPath, results, true); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting results to CSV: %v\n", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sortKeys lists the valid values of the --sort flag.
+var sortKeys = []string{"appid", "requests", "lastcalled", "type"}
+
+// sortAppResults sorts the results in place by key (appid, requests, lastcalled or type),
+// in descending order when desc is set. Ties are broken by app ID. Apps without a
+// last-called time are always listed last when sorting by lastcalled.
+func sortAppResults(results []AppResult, key string, desc bool) {
+	compare := func(a, b AppResult) int {
+		switch key {
+		case "requests":
+			return a.RequestCount - b.RequestCount
+		case "lastcalled":
+			return a.LastCalled.Compare(b.LastCalled)
+		case "type":
+			return strings.Compare(a.AppType, b.AppType)
+		}
+		return 0
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if key == "lastcalled" && a.LastCalled.IsZero() != b.LastCalled.IsZero() {
+			return b.LastCalled.IsZero()
+		}
+		c := compare(a, b)
+		if c == 0 {
+			c = strings.Compare(a.AppID, b.AppID)
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// printFormatted renders every result through tmpl, one per line, in place of the
+// summary table (see --format).
+func printFormatted(w io.Writer, tmpl *template.Template, results []AppResult) error {
+	for _, r := range results {
+		if err := tmpl.Execute(w, r); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
 // printSummary prints a condensed summary table for multiple apps.
-func printSummary(results []AppResult) {
+// With opts.measureLatency, the per-app query time and its percentiles are printed too.
+// With opts.showErrors, the error of the apps that could not be monitored is printed too.
+func printSummary(results []AppResult, opts monitorOptions) {
 	fmt.Println("")
-	printAppsSummaryTable(results)
+	output.Render(os.Stdout, output.Table, appsSummary{results: results, opts: opts})
+	if opts.measureLatency {
+		printLatencyPercentiles(results)
+	}
 }
 
 // printAppsSummaryTable prints a condensed table of app monitoring results
-// using tabwriter for alignment.
-func printAppsSummaryTable(results []AppResult) {
+// using tabwriter for alignment, with the latency and error columns selected by opts.
+func printAppsSummaryTable(out io.Writer, results []AppResult, opts monitorOptions) {
 	// Create a new tabwriter with a minimum width of 0, tab width of 8,
 	// padding of 2, and using a tab ('\t') as the padding character.
-	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
 
-	// Print header row.
-	fmt.Fprintln(w, "App ID\tType\tLast Called\tRequest Count")
-	fmt.Fprintln(w, "------\t----\t-----------\t-------------")
+	header, numeric, rows, footer := summaryRows(results, opts.measureLatency, opts.showErrors)
 
-	// Iterate over the results and print each row.
-	for _, r := range results {
-		var lastCalled string
-		if r.LastCalled.IsZero() {
-			lastCalled = "No data"
-		} else {
-			lastCalled = r.LastCalled.Format(time.RFC1123)
+	// Right-align the numeric columns by padding their cells to the widest one, header
+	// included; tabwriter then only pads them on the right like the text columns.
+	cells := append(rows[:len(rows):len(rows)], footer)
+	underline := make([]string, len(header))
+	for i, h := range header {
+		width := utf8.RuneCountInString(h)
+		if numeric[i] {
+			for _, row := range cells {
+				if i < len(row) {
+					width = max(width, utf8.RuneCountInString(row[i]))
+				}
+			}
+			header[i] = padLeft(h, width)
+			for _, row := range cells {
+				if i < len(row) && row[i] != "" {
+					row[i] = padLeft(row[i], width)
+				}
+			}
 		}
+		underline[i] = strings.Repeat("-", width)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	fmt.Fprintln(w, strings.Join(underline, "\t"))
+	for _, row := range rows {
 		// Each column is separated by a tab character.
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", r.AppID, r.AppType, lastCalled, r.RequestCount)
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if footer != nil {
+		fmt.Fprintln(w, strings.Join(underline, "\t"))
+		fmt.Fprintln(w, strings.Join(footer, "\t"))
 	}
 
 	// Flush the writer to ensure output is written.
 	w.Flush()
 }
 
+// padLeft right-aligns s in a cell of the given width.
+func padLeft(s string, width int) string {
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// summaryRows returns the header, which of its columns are numeric, the rows and, with
+// --totals, the footer of the summary of the results, shared by the table and Markdown
+// outputs. The apps that could not be monitored show "Error" instead of their last-called
+// time and request count, so they are not mistaken for idle apps.
+func summaryRows(results []AppResult, showLatency, showErrors bool) (header []string, numeric []bool, rows [][]string, footer []string) {
+	// Build the header row; optional columns come last.
+	addColumn := func(name string, isNumeric bool) {
+		header = append(header, name)
+		numeric = append(numeric, isNumeric)
+	}
+	for _, name := range []string{"App ID", "Type", "Cluster", "Labels", "Last Called"} {
+		addColumn(name, false)
+	}
+	addColumn("Request Count", true)
+	if showLatency {
+		addColumn("Query ms", true)
+	}
+	if showRuntime {
+		addColumn("Runtime", false)
+	}
+	if len(results) > 0 {
+		for _, m := range results[0].Resources {
+			addColumn(resourceHeaders[m.Name], true)
+		}
+		for _, m := range results[0].Metrics {
+			addColumn(m.Name, true)
+		}
+	}
+	if showErrors {
+		addColumn("Error", false)
+	}
+
+	// Iterate over the results and build each row.
+	for _, r := range results {
+		lastCalled := "No data"
+		if !r.LastCalled.IsZero() {
+			lastCalled = formatLastCalled(r.LastCalled)
+		}
+		cluster := r.Cluster
+		if cluster == "" {
+			cluster = "-"
+		}
+		requestCount := strconv.Itoa(r.RequestCount)
+		if r.Err != nil {
+			lastCalled, requestCount = "Error", "-"
+		}
+		row := []string{r.AppID, r.AppType, cluster, formatLabels(r.Labels), lastCalled, requestCount}
+		if showLatency {
+			row = append(row, strconv.FormatInt(r.QueryTime.Milliseconds(), 10))
+		}
+		if showRuntime {
+			rt := runtimeResult(r.app, defaultEOLWithin, time.Now())
+			row = append(row, fmt.Sprintf("%s (%s)", rt.MuleVersion, rt.Status))
+		}
+		for _, m := range r.Resources {
+			row = append(row, formatMetricValue(m))
+		}
+		for _, m := range r.Metrics {
+			row = append(row, formatMetricValue(m))
+		}
+		if showErrors {
+			errMsg := "-"
+			if r.Err != nil {
+				errMsg = r.Err.Error()
+			}
+			row = append(row, errMsg)
+		}
+		rows = append(rows, row)
+	}
+
+	// Add the totals in the App ID, Type, Last Called and Request Count columns.
+	if showTotals && len(results) > 0 {
+		var requests, idle int
+		for _, r := range results {
+			requests += r.RequestCount
+			if r.Err == nil && r.RequestCount == 0 {
+				idle++
+			}
+		}
+		footer = make([]string, len(header))
+		footer[0], footer[1] = "Total", fmt.Sprintf("%d apps", len(results))
+		footer[4], footer[5] = fmt.Sprintf("%d idle", idle), strconv.Itoa(requests)
+	}
+	return header, numeric, rows, footer
+}
+
+// formatLastCalled formats a last-called time for the table outputs: as a duration
+// relative to now with --relative-time, in RFC1123 otherwise.
+func formatLastCalled(t time.Time) string {
+	if relativeTime {
+		return humanizeDuration(time.Since(t))
+	}
+	return t.Format(time.RFC1123)
+}
+
+// humanizeDuration renders how long ago something happened, e.g. "3 hours ago", in the
+// largest whole unit among minutes, hours and days.
+func humanizeDuration(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int64(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return plural(int64(d/time.Hour), "hour")
+	default:
+		return plural(int64(d/(24*time.Hour)), "day")
+	}
+}
+
+// formatLabels renders app labels as "key=value" pairs sorted by key, or "-" when there are none.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// parseLabelFilters turns "key=value" selectors into app filters.
+func parseLabelFilters(selectors []string) ([]anypoint.AppFilter, error) {
+	var filters []anypoint.AppFilter
+	for _, selector := range selectors {
+		key, value, ok := strings.Cut(selector, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label selector %q, expected key=value", selector)
+		}
+		filters = append(filters, anypoint.FilterByLabel(key, value))
+	}
+	return filters, nil
+}
+
+// latencyPercentile returns the p-th percentile (nearest rank) of the sorted durations.
+func latencyPercentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// printLatencyPercentiles prints the p50, p90, p99 and max query times of the results.
+func printLatencyPercentiles(results []AppResult) {
+	durations := make([]time.Duration, 0, len(results))
+	for _, r := range results {
+		durations = append(durations, r.QueryTime)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	fmt.Printf("\nQuery ms: p50=%d p90=%d p99=%d max=%d\n",
+		latencyPercentile(durations, 50).Milliseconds(),
+		latencyPercentile(durations, 90).Milliseconds(),
+		latencyPercentile(durations, 99).Milliseconds(),
+		latencyPercentile(durations, 100).Milliseconds(),
+	)
+}
+
 // printDetailedResult prints detailed monitoring info for a single app.
-func printDetailedResult(res AppResult) {
+func printDetailedResult(res AppResult, showLatency bool) {
 	data := map[string]interface{}{
 		"App ID":           res.AppID,
 		"Last Called Time": res.LastCalled,
@@ -164,9 +979,326 @@ func printDetailedResult(res AppResult) {
 		"LC Window":        res.LCWindow,
 		"RC Window":        res.RCWindow,
 	}
+	if relativeTime {
+		data["Last Called Time"] = "No data"
+		if !res.LastCalled.IsZero() {
+			data["Last Called Time"] = formatLastCalled(res.LastCalled)
+		}
+	}
+	if res.Cluster != "" {
+		data["Cluster"] = res.Cluster
+	}
+	if len(res.Labels) > 0 {
+		data["Labels"] = formatLabels(res.Labels)
+	}
+	if showLatency {
+		data["Query ms"] = res.QueryTime.Milliseconds()
+	}
+	for _, m := range res.Resources {
+		data[resourceHeaders[m.Name]] = formatMetricValue(m)
+	}
+	for _, m := range res.Metrics {
+		data[m.Name] = formatMetricValue(m)
+	}
 	PrintSimpleResults("Monitoring Results", data)
 }
 
+// influxTagEscaper escapes the characters that are special in line protocol tag values.
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// escapeInfluxTag escapes commas, equal signs and spaces in a line protocol tag value.
+func escapeInfluxTag(value string) string {
+	return influxTagEscaper.Replace(value)
+}
+
+// printInfluxLine prints the monitoring results as InfluxDB line protocol so they can be
+// written back into a time-series database. Every line is stamped with the collection time.
+func printInfluxLine(w io.Writer, results []AppResult) {
+	ts := time.Now().UnixNano()
+	for _, r := range results {
+		tags := fmt.Sprintf("app_id=%s,type=%s", escapeInfluxTag(r.AppID), escapeInfluxTag(r.AppType))
+		fmt.Fprintf(w, "muletracker_requests,%s value=%di %d\n", tags, r.RequestCount, ts)
+		// Only emit the last-called point when data is available.
+		if !r.LastCalled.IsZero() {
+			fmt.Fprintf(w, "muletracker_last_called,%s value=%di %d\n", tags, r.LastCalled.UnixMilli(), ts)
+		}
+	}
+}
+
+// writeInfluxLines posts the results as line protocol to an InfluxDB write endpoint, e.g.
+// http://localhost:8086/write?db=monitoring. A non-empty token is sent as an InfluxDB 2
+// "Token" authorization. Non-2xx responses are returned as errors.
+func writeInfluxLines(ctx context.Context, url, token string, results []AppResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	printInfluxLine(&body, results)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting line protocol: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("write endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// monitorReport is the JSON envelope wrapping the monitoring results with the
+// provenance of the run (when, how long, on what and with which windows).
+type monitorReport struct {
+	RunAt      time.Time       `json:"runAt"`
+	DurationMs int64           `json:"durationMs"`
+	OrgID      string          `json:"orgId"`
+	EnvID      string          `json:"envId"`
+	Window     reportWindow    `json:"window"`
+	Counts     reportCounts    `json:"counts"`
+	Results    []appResultJSON `json:"results"`
+	Error      string          `json:"error,omitempty"` // the error that stopped the run, if any
+}
+
+// writeErrorReport writes report, for the json output, with the error that stopped the
+// run, so that stdout still carries a valid JSON document.
+func writeErrorReport(path string, report monitorReport, err error) {
+	report.Error = err.Error()
+	if err := writeOutput(path, func(w io.Writer) { printJSON(w, report) }); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+	}
+}
+
+// reportWindow holds the time windows used in the queries.
+type reportWindow struct {
+	LastCalled   string `json:"lastCalled"`
+	RequestCount string `json:"requestCount"`
+}
+
+// reportCounts summarizes how many apps were monitored, failed and reported.
+type reportCounts struct {
+	Apps    int `json:"apps"`
+	Failed  int `json:"failed"`
+	Results int `json:"results"`
+}
+
+// appResultJSON is the JSON representation of an AppResult.
+type appResultJSON struct {
+	AppID        string             `json:"appId"`
+	AppType      string             `json:"appType"`
+	Cluster      string             `json:"cluster,omitempty"`
+	Labels       map[string]string  `json:"labels,omitempty"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+	Resources    map[string]float64 `json:"resources,omitempty"`
+	LastCalled   *time.Time         `json:"lastCalled"`
+	RequestCount int                `json:"requestCount"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// newMonitorReport builds the JSON envelope for a run started at runAt.
+// apps is the number of monitored apps, before any filter is applied.
+func newMonitorReport(runAt time.Time, orgID, envID, lcWindow, rcWindow string, apps int, all, results []AppResult) monitorReport {
+	report := monitorReport{
+		RunAt:      runAt,
+		DurationMs: time.Since(runAt).Milliseconds(),
+		OrgID:      orgID,
+		EnvID:      envID,
+		Window:     reportWindow{LastCalled: lcWindow, RequestCount: rcWindow},
+		Counts:     reportCounts{Apps: apps, Results: len(results)},
+		Results:    make([]appResultJSON, 0, len(results)),
+	}
+	for _, r := range all {
+		if r.Err != nil {
+			report.Counts.Failed++
+		}
+	}
+	for _, r := range results {
+		jr := appResultJSON{
+			AppID:        r.AppID,
+			AppType:      r.AppType,
+			Cluster:      r.Cluster,
+			Labels:       r.Labels,
+			RequestCount: r.RequestCount,
+		}
+		if !r.LastCalled.IsZero() {
+			lastCalled := r.LastCalled
+			jr.LastCalled = &lastCalled
+		}
+		if r.Err != nil {
+			jr.Error = r.Err.Error()
+		}
+		for _, m := range r.Metrics {
+			if !m.HasData {
+				continue
+			}
+			if jr.Metrics == nil {
+				jr.Metrics = make(map[string]float64)
+			}
+			jr.Metrics[m.Name] = m.Value
+		}
+		for _, m := range r.Resources {
+			if !m.HasData {
+				continue
+			}
+			if jr.Resources == nil {
+				jr.Resources = make(map[string]float64)
+			}
+			jr.Resources[m.Name] = m.Value
+		}
+		report.Results = append(report.Results, jr)
+	}
+	return report
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(w io.Writer, v interface{}) {
+	if err := output.WriteJSON(w, v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON output: %v\n", err)
+	}
+}
+
+// appsSummary renders the monitoring results of a run in the shared output formats.
+type appsSummary struct {
+	report  monitorReport
+	results []AppResult
+	opts    monitorOptions
+}
+
+func (s appsSummary) RenderTable(w io.Writer) error {
+	printAppsSummaryTable(w, s.results, s.opts)
+	return nil
+}
+
+func (s appsSummary) RenderJSON(w io.Writer) error {
+	return output.WriteJSON(w, s.report)
+}
+
+func (s appsSummary) RenderCSV(w io.Writer) error {
+	return writeResultsCSV(w, s.results, time.Time{}, true)
+}
+
+// RenderMarkdown renders the summary table as a Markdown table, e.g. to paste it into an issue.
+func (s appsSummary) RenderMarkdown(w io.Writer) error {
+	header, _, rows, footer := summaryRows(s.results, s.opts.measureLatency, s.opts.showErrors)
+	if footer != nil {
+		rows = append(rows, footer)
+	}
+	return output.WriteMarkdown(w, header, rows)
+}
+
+// printResults prints the results in the given machine-readable format: influx-line, json, csv, timeseries or markdown.
+func printResults(w io.Writer, format string, report monitorReport, results []AppResult) {
+	switch format {
+	case output.InfluxLine:
+		printInfluxLine(w, results)
+	case output.JSON, output.CSV:
+		if err := output.Render(w, format, appsSummary{report: report, results: results}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s output: %v\n", strings.ToUpper(format), err)
+		}
+	case output.Timeseries:
+		if err := writeRequestSeriesCSV(w, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing CSV output: %v\n", err)
+		}
+	case output.Markdown:
+		if err := (appsSummary{report: report, results: results}).RenderMarkdown(w); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing Markdown output: %v\n", err)
+		}
+	}
+}
+
+// writeOutput calls write with the file at path, or with stdout when path is empty.
+func writeOutput(path string, write func(w io.Writer)) error {
+	if path == "" {
+		write(os.Stdout)
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	write(f)
+	return f.Close()
+}
+
+// isReplayFlag reports whether the flag controls saving/replaying and must not be persisted itself.
+func isReplayFlag(name string) bool {
+	return name == "save-last" || name == "replay-last"
+}
+
+// isSecretFlag reports whether the flag carries a secret, e.g. a token or a webhook URL,
+// which must not be persisted in clear text with --save-last.
+func isSecretFlag(name string) bool {
+	return name == "influx-write-token" || name == "slack-webhook"
+}
+
+// saveLastInvocation persists the full monitor flag set to the configuration so that
+// it can be re-run later with --replay-last.
+func saveLastInvocation(flags *pflag.FlagSet) error {
+	saved := make(map[string]string)
+	flags.VisitAll(func(f *pflag.Flag) {
+		if isReplayFlag(f.Name) || isSecretFlag(f.Name) {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			saved[f.Name] = strings.Join(sv.GetSlice(), ",")
+			return
+		}
+		saved[f.Name] = f.Value.String()
+	})
+	viper.Set(config.Key(lastMonitorKey), saved)
+	return config.SaveConfig()
+}
+
+// isDefaultValue reports whether the value saved by --save-last for f is its default.
+func isDefaultValue(f *pflag.Flag, value string) bool {
+	if _, ok := f.Value.(pflag.SliceValue); ok {
+		return "["+value+"]" == f.DefValue
+	}
+	return value == f.DefValue
+}
+
+// replayLastInvocation applies the flags saved by --save-last.
+// Flags explicitly set on the current command line take precedence over the saved ones.
+// Saved values equal to the default are skipped, so that the flags left unset in the
+// saved run are not marked as changed, e.g. the windows of a --since run.
+func replayLastInvocation(flags *pflag.FlagSet) error {
+	saved := viper.GetStringMapString(config.Key(lastMonitorKey))
+	if len(saved) == 0 {
+		return fmt.Errorf("no saved monitor invocation found. Run 'monitor' with --save-last first")
+	}
+	for name, value := range saved {
+		f := flags.Lookup(name)
+		if f == nil || f.Changed || isReplayFlag(name) || isSecretFlag(name) {
+			continue
+		}
+		if isDefaultValue(f, value) {
+			continue
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if value != "" {
+				values = strings.Split(value, ",")
+			}
+			if err := sv.Replace(values); err != nil {
+				return fmt.Errorf("invalid saved value for --%s: %w", name, err)
+			}
+			continue
+		}
+		if err := flags.Set(name, value); err != nil {
+			return fmt.Errorf("invalid saved value for --%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // ----- Main Command ----- //
 
 // monitorCmd represents the monitor command
@@ -177,107 +1309,498 @@ var monitorCmd = &cobra.Command{
 and request count for each app over specified time windows.
 
 If the --app flag is empty, all apps for the given org/env are monitored concurrently.
+Several apps may be given, e.g. --app orders-api,payments-api; they are monitored
+concurrently and summarized the same way, while a single app gets a detailed output.
+
+Use --since and --until (RFC3339 timestamps) to query an absolute time range, e.g. an
+incident window, instead of the --last-called-window and --request-count-window lookbacks.
 
 Filters:
   --filter: "all" (default), "nonempty" (only apps with monitoring data), or "empty" (only apps with no data)
-  --app-type: "all" (default), "cloudhub" (only CloudHub apps), or "rtf" (only RTF apps)
+  --app-type: "all" (default), "cloudhub" (only CloudHub apps), "cloudhub2" (only CloudHub 2.0 apps), or "rtf" (only RTF apps).
+              Required with --app when the same name is deployed to both CloudHub and RTF.
+  --label: only apps labeled key=value, e.g. --label criticality=high (repeatable)
+
+Apps deployed to targets that cannot be monitored yet are skipped with a warning listing
+their types. Use --strict-types to fail instead.
+
+Output:
+  --output: "table" (default) or "influx-line" (InfluxDB line protocol, e.g.
+            muletracker_requests,app_id=my-app,type=CLOUDHUB value=42i 1700000000000000000)
+            or "json" (results wrapped with the run timestamp, duration, org/env, windows and counts)
+            or "csv" or "timeseries" (requests per minute of every app over the request-count
+            window, as App ID,Time,Requests CSV rows, for charting) or "markdown" (the summary
+            table as a GitHub-flavored Markdown table, e.g. for Confluence or GitHub issues)
+  --format: render each result with a Go template instead of the table, one line per app,
+            e.g. '{{.AppID}},{{.RequestCount}}'. The fields are AppID, AppType, Cluster,
+            Labels, LastCalled, RequestCount, Err, LCWindow and RCWindow.
+
+Deployment-health gate:
+  --fail-if-idle: with --app, exit non-zero if the app received no request over the
+                  request-count window (or was last called longer ago than --idle-threshold)
+
+CSV export:
+  --csv: also export the results to a CSV file, overwritten on each run
+  --csv-append: append the results as a snapshot with a "Snapshot Time" column instead,
+                building a time series across runs
+
+Custom metrics:
+  --metrics-file: YAML file defining extra InfluxDB metrics, computed for each app and
+                  shown as extra columns (see README for the schema)
+
+Replay:
+  --save-last: persist the full flag set of this invocation to the configuration
+  --replay-last: re-run with the saved flags (flags given on the command line still win)
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Retrieve the context from the command.
 		ctx := cmd.Context()
 
+		// Restore the saved invocation before reading any flag.
+		replayLast, _ := cmd.Flags().GetBool("replay-last")
+		if replayLast {
+			if err := replayLastInvocation(cmd.Flags()); err != nil {
+				fmt.Printf("Error replaying last invocation: %v\n", err)
+				return
+			}
+		}
+
 		// Retrieve flag values.
-		orgID, _ := cmd.Flags().GetString("org")
-		envID, _ := cmd.Flags().GetString("env")
-		appID, _ := cmd.Flags().GetString("app")
-		lcWindow, _ := cmd.Flags().GetString("last-called-window")
-		rcWindow, _ := cmd.Flags().GetString("request-count-window")
-		dataFilter, _ := cmd.Flags().GetString("filter")
-		appType, _ := cmd.Flags().GetString("app-type")
+		opts := newMonitorOptions(cmd.Flags())
+		// A single app gets the detailed output; several go through the summary like all apps.
+		var appID string
+		if len(opts.appIDs) == 1 {
+			appID = opts.appIDs[0]
+		}
+
+		// Validate the output format.
+		opts.format = strings.ToLower(opts.format)
+		if err := output.Validate(opts.format, output.Table, output.InfluxLine, output.JSON, output.CSV, output.Timeseries, output.Markdown); err != nil {
+			fmt.Println(err)
+			return
+		}
+		for _, window := range []string{opts.lcWindow, opts.rcWindow} {
+			if err := anypoint.ValidateWindow(window); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		if !slices.Contains(appStatuses, strings.ToLower(opts.appStatus)) {
+			fmt.Printf("Invalid app status. Valid values are %s.\n", strings.Join(appStatuses, ", "))
+			return
+		}
+		var nameFilters []anypoint.AppFilter
+		if opts.appName != "" {
+			nameFilters = append(nameFilters, anypoint.FilterByNameContains(opts.appName))
+		}
+		if opts.appNameRegex != "" {
+			re, err := regexp.Compile(opts.appNameRegex)
+			if err != nil {
+				fmt.Printf("Invalid --app-name-regex: %v\n", err)
+				return
+			}
+			nameFilters = append(nameFilters, anypoint.FilterByNameRegexp(re))
+		}
+		opts.sortKey = strings.ToLower(opts.sortKey)
+		if opts.sortKey != "" && !slices.Contains(sortKeys, opts.sortKey) {
+			fmt.Printf("Invalid sort key. Valid values are %s.\n", strings.Join(sortKeys, ", "))
+			return
+		}
+		var err error
+		if opts.resources, err = parseMetricsFlag(opts.metricNames); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if opts.concurrency < 1 {
+			fmt.Println("--concurrency must be at least 1.")
+			return
+		}
+		if opts.rate <= 0 {
+			fmt.Println("--rate must be positive.")
+			return
+		}
+		if opts.maxAttempts < 1 {
+			fmt.Println("--max-attempts must be at least 1.")
+			return
+		}
+		if opts.minRequests < 0 {
+			fmt.Println("--min-requests cannot be negative.")
+			return
+		}
+		if opts.maxRequests >= 0 && opts.maxRequests < opts.minRequests {
+			fmt.Println("--max-requests cannot be lower than --min-requests.")
+			return
+		}
+		if opts.watch && (opts.format != output.Table || appID != "") {
+			fmt.Println("--watch is only supported with the table output, when monitoring several apps.")
+			return
+		}
+		if opts.watch && opts.watchInterval <= 0 {
+			fmt.Println("--watch-interval must be positive.")
+			return
+		}
+		if opts.slackWebhook != "" && (opts.watch || appID != "") {
+			fmt.Println("--slack-webhook is only supported when monitoring several apps, without --watch.")
+			return
+		}
+		var tmpl *template.Template
+		if opts.formatTemplate != "" {
+			if opts.format != output.Table || opts.watch {
+				fmt.Println("--format replaces the table output and cannot be combined with --output or --watch.")
+				return
+			}
+			if tmpl, err = template.New("format").Parse(opts.formatTemplate); err != nil {
+				fmt.Printf("Invalid --format template: %v\n", err)
+				return
+			}
+		}
+		if opts.outputFile != "" && opts.format == output.Table {
+			fmt.Println("--output-file requires --output influx-line, json, csv, timeseries or markdown.")
+			return
+		}
+		if opts.influxWriteURL != "" && (opts.format != output.InfluxLine || opts.outputFile != "") {
+			fmt.Println("--influx-write-url requires --output influx-line and cannot be combined with --output-file.")
+			return
+		}
+		if opts.timezone != "" {
+			if err := anypoint.ValidateTimezone(opts.timezone); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		// Parse the absolute time range, which replaces the time windows.
+		if opts.sinceFlag != "" || opts.untilFlag != "" {
+			if cmd.Flags().Changed("last-called-window") || cmd.Flags().Changed("request-count-window") {
+				fmt.Println("--since and --until cannot be combined with --last-called-window or --request-count-window.")
+				return
+			}
+			if opts.since, err = parseRangeFlag("since", opts.sinceFlag); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if opts.until, err = parseRangeFlag("until", opts.untilFlag); err != nil {
+				fmt.Println(err)
+				return
+			}
+			if err := anypoint.ValidateTimeRange(opts.since, opts.until); err != nil {
+				fmt.Println(err)
+				return
+			}
+			opts.lcWindow = rangeWindow(opts.since, opts.until)
+			opts.rcWindow = opts.lcWindow
+		}
+		runAt := time.Now()
+		// Load the custom metric definitions.
+		if opts.metricsFile != "" {
+			if opts.metrics, err = LoadMetricsFile(opts.metricsFile); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		// Progress messages go to stderr whenever stdout carries machine-readable output.
+		info := io.Writer(os.Stdout)
+		if opts.format != output.Table || tmpl != nil {
+			info = os.Stderr
+		}
+
+		// Persist this invocation so it can be replayed with --replay-last.
+		if opts.saveLast {
+			if err := saveLastInvocation(cmd.Flags()); err != nil {
+				anypoint.Logger.Warn("unable to save monitor invocation", "err", err)
+			}
+		}
 
 		// Retrieve the previously connected client from context.
 		client, err := anypoint.GetClientFromContext()
 		if err != nil {
-			fmt.Printf("Error retrieving client: %v\n", err)
+			fmt.Fprintf(info, "Error retrieving client: %v\n", err)
+			if opts.format == output.JSON {
+				writeErrorReport(opts.outputFile, newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 0, nil, nil), err)
+			}
 			return
 		}
 
+		client.Timezone = opts.timezone
+		client.MaxAttempts = opts.maxAttempts
+		client.Since = opts.since
+		client.Until = opts.until
+
 		// Check that the required flags are provided.
-		if (client.IsOrgEmpty() && orgID == "") || (client.IsEnvEmpty() && envID == "") {
-			fmt.Println("Please provide --org, --env flags")
+		if (client.IsOrgEmpty() && opts.orgID == "") || (client.IsEnvEmpty() && opts.envID == "") {
+			err := errors.New("please provide the --org and --env flags")
+			fmt.Fprintln(info, err)
+			if opts.format == output.JSON {
+				writeErrorReport(opts.outputFile, newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 0, nil, nil), err)
+			}
 			return
 		}
 
-		// Save/Load org and env
-		if client.IsOrgEmpty() {
-			client.SetOrg(orgID)
-		} else {
-			orgID = client.Org
+		// Resolve the org and env given by name.
+		opts.orgID, opts.envID, err = resolveOrgEnv(ctx, client, opts.orgID, opts.envID)
+		if err != nil {
+			fmt.Fprintln(info, err)
+			if opts.format == output.JSON {
+				writeErrorReport(opts.outputFile, newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 0, nil, nil), err)
+			}
+			return
 		}
-		if client.IsEnvEmpty() {
-			client.SetEnv(envID)
-		} else {
-			envID = client.Env
+
+		// Save/Load org and env. Flags take precedence over MULETRACKER_ORG/MULETRACKER_ENV,
+		// which take precedence over the persisted configuration.
+		if opts.orgID == "" {
+			opts.orgID = client.Org
+		} else if client.IsOrgEmpty() {
+			client.SetOrg(opts.orgID)
+		}
+		if opts.envID == "" {
+			opts.envID = client.Env
+		} else if client.IsEnvEmpty() {
+			client.SetEnv(opts.envID)
 		}
 
 		// Display the client info in a colorful way.
-		PrintClientInfo(client)
-
-		// Build type filters based on app-type flag.
-		var typeFilters []anypoint.AppFilter = []anypoint.AppFilter{anypoint.FilterRunning}
-		switch strings.ToLower(appType) {
-		case "cloudhub":
-			typeFilters = append(typeFilters, anypoint.FilterCH1)
-			break
-		case "rtf":
-			typeFilters = append(typeFilters, anypoint.FilterRTF)
-			break
-		case "all":
-			typeFilters = append(typeFilters, anypoint.FilterCH1OrRTF)
-			break
+		if opts.format == output.Table && tmpl == nil {
+			PrintClientInfo(client)
 		}
 
+		// Show the settings this run uses, once flags, environment and configuration are merged.
+		if opts.printConfig {
+			app := strings.Join(opts.appIDs, ",")
+			if app == "" {
+				app = "(all)"
+			}
+			labels := strings.Join(opts.labelSelectors, ",")
+			if labels == "" {
+				labels = "(none)"
+			}
+			FprintSimpleResults(info, "Effective Configuration", map[string]interface{}{
+				"Control Plane":        anypoint.ControlPlane(client.ServerIndex),
+				"Org":                  opts.orgID,
+				"Env":                  opts.envID,
+				"App":                  app,
+				"Last-Called Window":   opts.lcWindow,
+				"Request-Count Window": opts.rcWindow,
+				"Filter":               opts.dataFilter,
+				"App Type":             opts.appType,
+				"App Status":           opts.appStatus,
+				"Labels":               labels,
+				"Concurrency":          opts.concurrency,
+				"Rate (req/s)":         opts.rate,
+				"Limit":                opts.limit,
+				"Retry Failed":         opts.retryFailed,
+				"Timezone":             client.QueryTimezone(),
+				"Output":               opts.format,
+			})
+		}
+
+		// Build filters based on the app-status and app-type flags.
+		typeFilters := append(appStatusFilters(opts.appStatus), appTypeFilters(opts.appType)...)
+		typeFilters = append(typeFilters, nameFilters...)
+
+		// Only keep the apps carrying all the requested labels.
+		labelFilters, err := parseLabelFilters(opts.labelSelectors)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		typeFilters = append(typeFilters, labelFilters...)
+
 		// Retrieve apps to monitor.
-		apps, err := getAppsToMonitor(ctx, client, orgID, envID, appID, typeFilters...)
+		apps, err := getAppsToMonitor(ctx, client, opts.orgID, opts.envID, opts.appIDs, opts.strictTypes, typeFilters...)
 		if err != nil {
-			fmt.Printf("Error retrieving apps: %v\n", err)
+			// Errors go to stderr, like the progress messages, unless the output is the table.
+			fmt.Fprintf(info, "Error retrieving apps: %v\n", err)
+			if opts.format == output.JSON {
+				writeErrorReport(opts.outputFile, newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 0, nil, nil), err)
+			}
 			return
 		}
 
 		if len(apps) == 0 {
-			fmt.Println("No apps found for the given org and env.")
+			fmt.Fprintln(info, "No apps found for the given org and env.")
+			// Keep stdout parseable: an empty report rather than nothing.
+			if opts.format != output.Table {
+				report := newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 0, nil, nil)
+				if err := writeOutput(opts.outputFile, func(w io.Writer) { printResults(w, opts.format, report, nil) }); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+				}
+			}
+			exitIfGated(opts.failIfIdle && appID != "")
 			return
 		}
 
 		// If a single app was specified, run in single-app mode.
 		if appID != "" {
-			result := monitorSingleApp(ctx, client, orgID, envID, apps[0], lcWindow, rcWindow)
+			app, err := resolveSingleApp(apps, appID)
+			if err != nil {
+				fmt.Fprintf(info, "Error monitoring app %s: %v\n", appID, err)
+				if opts.format == output.JSON {
+					writeErrorReport(opts.outputFile, newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, len(apps), nil, nil), err)
+				}
+				exitIfGated(opts.failIfIdle)
+				os.Exit(exitAppsFailed)
+			}
+			result := monitorSingleApp(ctx, client, opts.orgID, opts.envID, app, opts.lcWindow, opts.rcWindow)
 			if result.Err != nil {
-				fmt.Printf("Error monitoring app %s: %v\n", appID, result.Err)
-				return
+				fmt.Fprintf(info, "Error monitoring app %s: %v\n", appID, result.Err)
+				if opts.format == output.JSON {
+					// The failed result carries its error, as in a multi-app report.
+					results := []AppResult{result}
+					report := newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 1, results, results)
+					writeErrorReport(opts.outputFile, report, fmt.Errorf("error monitoring app %s: %w", appID, result.Err))
+				}
+				exitIfGated(opts.failIfIdle)
+				os.Exit(exitAppsFailed)
+			}
+			results := []AppResult{result}
+			resolveClusterNames(ctx, client, opts.orgID, results)
+			if len(opts.resources) > 0 {
+				collectResourceUsage(ctx, client, opts.orgID, opts.envID, opts.resources, results, opts.concurrency, opts.rate)
+			}
+			if len(opts.metrics) > 0 {
+				collectCustomMetrics(ctx, client, opts.orgID, opts.envID, opts.metrics, results, opts.concurrency, opts.rate)
 			}
-			printDetailedResult(result)
+			result = results[0]
+			if opts.csvPath != "" {
+				if err := ExportResultsToCSV(opts.csvPath, results, opts.csvAppend); err != nil {
+					fmt.Fprintf(os.Stderr, "Error exporting results to CSV: %v\n", err)
+				}
+			}
+			if tmpl != nil {
+				if err := printFormatted(os.Stdout, tmpl, results); err != nil {
+					fmt.Printf("Error rendering --format template: %v\n", err)
+				}
+			} else if opts.format == output.Table {
+				printDetailedResult(result, opts.measureLatency)
+			} else {
+				report := newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, 1, results, results)
+				if opts.influxWriteURL != "" {
+					if err := writeInfluxLines(ctx, opts.influxWriteURL, opts.influxWriteToken, results); err != nil {
+						fmt.Fprintf(os.Stderr, "Error writing to InfluxDB: %v\n", err)
+					}
+				} else if err := writeOutput(opts.outputFile, func(w io.Writer) { printResults(w, opts.format, report, results) }); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+				}
+			}
+			if reason := idleReason(result, opts.idleThreshold, time.Now()); opts.failIfIdle && reason != "" {
+				fmt.Fprintf(os.Stderr, "App %s is idle: %s\n", appID, reason)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Refresh the summary table until interrupted.
+		if opts.watch {
+			watchApps(ctx, client, opts, apps)
 			return
 		}
 
+		// Stream the CSV export as the results are collected, unless they must be sorted or
+		// retried first.
+		streamCSV := opts.csvPath != "" && opts.sortKey == "" && !opts.retryFailed
+		var csvStream *CSVResultWriter
+		var onResult func(AppResult)
+		if streamCSV {
+			if csvStream, err = NewCSVResultWriter(opts.csvPath, opts.csvAppend); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting results to CSV: %v\n", err)
+			} else {
+				onResult = newCSVStream(ctx, client, opts.orgID, csvStream, opts.dataFilter, opts.minRequests, opts.maxRequests)
+			}
+		}
+
 		// Monitor all apps concurrently.
-		allResults := monitorAppsConcurrently(ctx, client, orgID, envID, lcWindow, rcWindow, apps)
-		fmt.Printf("\n* Using last-called window: %s\n", lcWindow)
-		fmt.Printf("* Using request count window: %s\n", rcWindow)
-		fmt.Printf("* Found %d apps to monitor.\n", len(apps))
-		fmt.Printf("* Collected monitoring data for %d apps.\n", len(allResults))
+		allResults := monitorAppsConcurrently(ctx, client, opts, apps, opts.limit, onResult)
+		fmt.Fprintf(info, "\n* Using last-called window: %s\n", opts.lcWindow)
+		fmt.Fprintf(info, "* Using request count window: %s\n", opts.rcWindow)
+		fmt.Fprintf(info, "* Found %d apps to monitor.\n", len(apps))
+		fmt.Fprintf(info, "* Collected monitoring data for %d apps.\n", len(allResults))
+
+		// On Ctrl-C, report what was collected so far without querying further.
+		if ctx.Err() != nil {
+			fmt.Fprintf(info, "* Interrupted: reporting the %d apps monitored so far.\n", len(allResults))
+		} else {
+			// Optionally retry the apps that failed during the concurrent run.
+			if opts.retryFailed {
+				var attempted, recovered int
+				allResults, attempted, recovered = retryFailedResults(ctx, client, opts, allResults)
+				fmt.Fprintf(info, "* Retried %d failed apps, recovered %d.\n", attempted, recovered)
+			}
+
+			// Show RTF fabric names instead of opaque cluster IDs.
+			resolveClusterNames(ctx, client, opts.orgID, allResults)
+
+			// Compute the resource usage selected with --metrics.
+			if len(opts.resources) > 0 {
+				collectResourceUsage(ctx, client, opts.orgID, opts.envID, opts.resources, allResults, opts.concurrency, opts.rate)
+				fmt.Fprintf(info, "* Collected %s usage.\n", strings.Join(opts.resources, " and "))
+			}
+
+			// Compute the custom metrics as extra columns.
+			if len(opts.metrics) > 0 {
+				collectCustomMetrics(ctx, client, opts.orgID, opts.envID, opts.metrics, allResults, opts.concurrency, opts.rate)
+				fmt.Fprintf(info, "* Collected %d custom metrics.\n", len(opts.metrics))
+			}
+		}
 
 		// Apply filter.
-		finalResults := filterAppResults(allResults, dataFilter)
-		fmt.Printf("* After applying filter '%s', %d apps remain.\n", dataFilter, len(finalResults))
+		finalResults := filterAppResults(allResults, opts.dataFilter)
+		fmt.Fprintf(info, "* After applying filter '%s', %d apps remain.\n", opts.dataFilter, len(finalResults))
+		if opts.minRequests > 0 || opts.maxRequests >= 0 {
+			finalResults = filterRequestThresholds(finalResults, opts.minRequests, opts.maxRequests)
+			fmt.Fprintf(info, "* After thresholds (%s), %d apps remain.\n", describeThresholds(opts.minRequests, opts.maxRequests), len(finalResults))
+		}
+		if opts.sortKey != "" {
+			sortAppResults(finalResults, opts.sortKey, opts.sortDesc)
+		}
+		if csvStream != nil {
+			if err := csvStream.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting results to CSV: %v\n", err)
+			} else {
+				fmt.Fprintf(info, "* Exported %d apps to %s.\n", csvStream.Count(), opts.csvPath)
+			}
+		} else if opts.csvPath != "" && !streamCSV {
+			if err := ExportResultsToCSV(opts.csvPath, finalResults, opts.csvAppend); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting results to CSV: %v\n", err)
+			} else {
+				fmt.Fprintf(info, "* Exported %d apps to %s.\n", len(finalResults), opts.csvPath)
+			}
+		}
 		if len(finalResults) == 0 {
-			fmt.Println("No apps match the filter criteria.")
-			return
+			fmt.Fprintln(info, "No apps match the filter criteria.")
+		}
+		if opts.format != output.Table {
+			report := newMonitorReport(runAt, opts.orgID, opts.envID, opts.lcWindow, opts.rcWindow, len(apps), allResults, finalResults)
+			if opts.influxWriteURL != "" {
+				if err := writeInfluxLines(ctx, opts.influxWriteURL, opts.influxWriteToken, finalResults); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing to InfluxDB: %v\n", err)
+				} else {
+					fmt.Fprintf(info, "* Wrote %d apps to %s.\n", len(finalResults), opts.influxWriteURL)
+				}
+			} else if err := writeOutput(opts.outputFile, func(w io.Writer) { printResults(w, opts.format, report, finalResults) }); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			}
+		} else if tmpl != nil {
+			if err := printFormatted(os.Stdout, tmpl, finalResults); err != nil {
+				fmt.Printf("Error rendering --format template: %v\n", err)
+			}
+		} else if len(finalResults) > 0 {
+			// Print a summary if there are multiple apps.
+			printSummary(finalResults, opts)
 		}
 
-		// Print a summary if there are multiple apps.
-		printSummary(finalResults)
+		// Notify the apps that received no requests.
+		if opts.slackWebhook != "" && ctx.Err() == nil {
+			idle := idleApps(allResults)
+			title := fmt.Sprintf("%d apps received no requests %s", len(idle), overWindow(opts.rcWindow))
+			if err := notify.NewSlackNotifier(opts.slackWebhook).Notify(ctx, title, idle); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending Slack notification: %v\n", err)
+			} else if len(idle) > 0 {
+				fmt.Fprintf(info, "* Notified %d idle apps to Slack.\n", len(idle))
+			}
+		}
 
+		// Let automation detect partial failures.
+		exitIfAppsFailed(allResults)
 	},
 }
 
@@ -286,17 +1809,90 @@ func init() {
 	rootCmd.AddCommand(monitorCmd)
 
 	// Define flags for organization, environment, and application IDs.
-	monitorCmd.Flags().String("org", "", "Organization ID")
-	monitorCmd.Flags().String("env", "", "Environment ID")
-	monitorCmd.Flags().String("app", "", "Application ID to monitor")
+	monitorCmd.Flags().String("org", "", "Organization ID or name")
+	monitorCmd.Flags().String("env", "", "Environment ID or name")
+	monitorCmd.Flags().StringSlice("app", nil, "Application name or ID to monitor; repeat it or separate with commas to monitor several")
+	monitorCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
+	monitorCmd.RegisterFlagCompletionFunc("env", completePersisted("env"))
 
 	// Define flags for specifying the time window for queries.
 	monitorCmd.Flags().String("last-called-window", "15m", "Time window for last-called query (e.g., 15m, 1h, 24h)")
 	monitorCmd.Flags().String("request-count-window", "24h", "Time window for request count query (e.g., 24h, 3d)")
+	monitorCmd.Flags().String("since", "", "Start of an absolute time range to query instead of the time windows, as an RFC3339 timestamp (e.g. 2024-05-02T14:00:00+02:00)")
+	monitorCmd.Flags().String("until", "", "End of the absolute time range, as an RFC3339 timestamp (default is now)")
+	monitorCmd.Flags().String("timezone", "", "IANA time zone the monitoring queries group their results in, e.g. Europe/Paris (default is the system time zone)")
 
 	// Define a flag to filter the results.
 	monitorCmd.Flags().String("filter", "all", "Filter results: all (default), nonempty (only apps with monitoring data), or empty (only apps with no data)")
-	monitorCmd.Flags().String("app-type", "all", "Filter apps by type: all (default), cloudhub (only CloudHub apps), or rtf (only RTF apps)")
+	monitorCmd.Flags().Int("min-requests", 0, "Only show apps with at least this many requests over the request count window, after --filter")
+	monitorCmd.Flags().Int("max-requests", -1, "Only show apps with at most this many requests over the request count window, after --filter (-1 = no maximum)")
+	monitorCmd.Flags().String("sort", "", "Sort the results by appid, requests, lastcalled or type (default is the completion order)")
+	monitorCmd.Flags().Bool("sort-desc", false, "Sort the results in descending order")
+	monitorCmd.Flags().StringSlice("label", nil, "Only monitor apps with this label, as key=value (repeatable)")
+	monitorCmd.Flags().String("app-type", "all", "Filter apps by type: all (default), cloudhub (only CloudHub apps), cloudhub2 (only CloudHub 2.0 apps), or rtf (only RTF apps)")
+	monitorCmd.RegisterFlagCompletionFunc("app-type", completeValues("all", "cloudhub", "cloudhub2", "rtf"))
+	monitorCmd.Flags().String("app-name", "", "Only monitor apps whose name contains this text (case-insensitive)")
+	monitorCmd.Flags().String("app-name-regex", "", "Only monitor apps whose name matches this regular expression, e.g. ^payments-")
+	monitorCmd.Flags().String("app-status", "running", "Filter apps by status: running (default), stopped, undeployed or all")
+	monitorCmd.RegisterFlagCompletionFunc("app-status", completeValues(appStatuses...))
+	monitorCmd.RegisterFlagCompletionFunc("filter", completeValues("all", "nonempty", "empty"))
+	monitorCmd.RegisterFlagCompletionFunc("sort", completeValues(sortKeys...))
+
+	// Define a flag to fail instead of warning on apps deployed to unsupported targets.
+	monitorCmd.Flags().Bool("strict-types", false, "Fail when apps are deployed to targets that cannot be monitored instead of warning")
+
+	// Define a flag to stop once enough apps have been monitored.
+	monitorCmd.Flags().Int("limit", 0, "Stop once this many apps have been monitored, cancelling the remaining queries (0 = no limit)")
+
+	// Define a flag to retry the failing monitoring queries.
+	monitorCmd.Flags().Int("max-attempts", anypoint.DefaultMaxAttempts, "Number of attempts of each monitoring query failing with 429, 5xx or a network error, with exponential backoff")
+
+	// Define a flag to retry the apps that failed during the concurrent run.
+	monitorCmd.Flags().Bool("retry-failed", false, "Retry apps whose monitoring queries failed in a second, lower-concurrency pass")
+
+	// Define a flag to report why apps could not be monitored.
+	monitorCmd.Flags().Bool("show-errors", false, "Add an Error column with the reason the apps that could not be monitored failed")
+
+	// Define a flag to report the per-app query latency.
+	monitorCmd.Flags().Bool("measure-latency", false, "Show the time spent querying each app (Query ms column) and its percentiles")
+
+	// Define flags to use single-app mode as a deployment-health gate.
+	monitorCmd.Flags().Bool("fail-if-idle", false, "With --app, exit with a non-zero status if the app is idle or cannot be monitored")
+	monitorCmd.Flags().Duration("idle-threshold", 0, "With --fail-if-idle, also consider the app idle if last called longer ago than this (e.g., 30m)")
+
+	// Define flags to select the built-in metrics and load custom metric definitions.
+	monitorCmd.Flags().StringSlice("metrics", []string{"requests", "lastcalled"}, "Metrics to collect: requests, lastcalled, cpu, memory (requests and lastcalled are always collected)")
+	monitorCmd.Flags().String("metrics-file", "", "YAML file defining custom InfluxDB metrics shown as extra columns")
+
+	// Define a flag to notify the idle apps to Slack.
+	monitorCmd.Flags().String("slack-webhook", "", "Post the apps that received no requests to this Slack incoming webhook URL")
+
+	// Define flags to export the results to a CSV file.
+	monitorCmd.Flags().String("csv", "", "Also export the results to this CSV file (overwritten by default)")
+	monitorCmd.Flags().Bool("csv-append", false, "Append the results to the CSV file as a timestamped snapshot instead of overwriting it")
+
+	monitorCmd.Flags().BoolVar(&showRuntime, "show-runtime", false, "Add a Runtime column with the Mule version of each app and whether its support ends within 90 days")
+	monitorCmd.Flags().BoolVar(&showTotals, "totals", true, "Add a row with the total apps, requests and idle apps to the summary table")
+	monitorCmd.Flags().BoolVar(&relativeTime, "relative-time", false, "Show the last-called times relative to now, e.g. \"3 hours ago\", in the table output")
+	monitorCmd.Flags().String("format", "", "Render each result with this Go template instead of the table, e.g. '{{.AppID}},{{.RequestCount}}'")
+	monitorCmd.Flags().String("influx-write-url", "", "With --output influx-line, post the lines to this InfluxDB write URL (e.g. http://localhost:8086/write?db=monitoring) instead of stdout")
+	monitorCmd.Flags().String("influx-write-token", "", "InfluxDB 2 API token sent with --influx-write-url")
+	monitorCmd.Flags().String("output-file", "", "Write the influx-line, json, csv, timeseries or markdown output to this file instead of stdout")
+
+	// Define the flags tuning the load put on the monitoring API.
+	monitorCmd.Flags().Int("concurrency", defaultConcurrency, "Number of apps monitored in parallel (higher values may trigger 429 responses)")
+	monitorCmd.Flags().Float64("rate", defaultRate, "Maximum number of monitoring requests per second (higher values may trigger 429 responses)")
+
+	// Define the flags of the watch mode.
+	monitorCmd.Flags().Bool("watch", false, "Re-run the monitoring on each interval and refresh the summary table, until interrupted")
+	monitorCmd.Flags().Duration("watch-interval", time.Minute, "Interval between two runs in watch mode")
+
+	// Define a flag to print the effective configuration of the run.
+	monitorCmd.Flags().Bool("print-config", false, "Print the settings used for the run after merging flags, environment and configuration")
+
+	// Define flags to persist and replay the monitor invocation.
+	monitorCmd.Flags().Bool("save-last", false, "Persist the flags of this invocation to the configuration")
+	monitorCmd.Flags().Bool("replay-last", false, "Re-run monitor with the flags saved by --save-last")
 
 	// Mark the required flags.
 	// monitorCmd.MarkFlagRequired("org")
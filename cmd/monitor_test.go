@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestReplayLastInvocationSkipsDefaults(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	flags := pflag.NewFlagSet("monitor", pflag.ContinueOnError)
+	flags.String("last-called-window", "15m", "")
+	flags.String("since", "", "")
+	flags.StringSlice("app", nil, "")
+	flags.StringSlice("metrics", []string{"requests"}, "")
+	viper.Set(config.Key(lastMonitorKey), map[string]string{
+		"last-called-window": "15m",
+		"since":              "2024-01-01T00:00:00Z",
+		"app":                "orders-api,payments-api",
+		"metrics":            "requests",
+	})
+
+	if err := replayLastInvocation(flags); err != nil {
+		t.Fatalf("replayLastInvocation: %v", err)
+	}
+	for _, name := range []string{"last-called-window", "metrics"} {
+		if flags.Changed(name) {
+			t.Errorf("--%s is marked changed although its saved value is the default", name)
+		}
+	}
+	if got, _ := flags.GetString("since"); got != "2024-01-01T00:00:00Z" {
+		t.Errorf("--since = %q, want the saved value", got)
+	}
+	if got, _ := flags.GetStringSlice("app"); len(got) != 2 {
+		t.Errorf("--app = %v, want the two saved apps", got)
+	}
+}
+
+func TestWriteErrorReportIsValidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	results := []AppResult{{AppID: "orders-api", AppType: "CLOUDHUB", Err: errors.New("request-count: timeout")}}
+	report := newMonitorReport(time.Now(), "org", "env", "15m", "24h", 1, results, results)
+
+	writeErrorReport(path, report, errors.New("error monitoring app orders-api: request-count: timeout"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got monitorReport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, data)
+	}
+	if got.Error == "" || got.Counts.Failed != 1 || len(got.Results) != 1 || got.Results[0].Error == "" {
+		t.Errorf("report = %+v, want the run error and the failed result", got)
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{-time.Minute, "just now"}, // clock skew
+		{59 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{59*time.Minute + 59*time.Second, "59 minutes ago"},
+		{time.Hour, "1 hour ago"},
+		{2 * time.Hour, "2 hours ago"},
+		{24*time.Hour - time.Second, "23 hours ago"},
+		{24 * time.Hour, "1 day ago"},
+		{47 * time.Hour, "1 day ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{400 * 24 * time.Hour, "400 days ago"},
+	}
+	for _, tt := range tests {
+		if got := humanizeDuration(tt.d); got != tt.want {
+			t.Errorf("humanizeDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSingleAppOnSeveralTargets(t *testing.T) {
+	var ch1, rtf, other anypoint.App
+	ch1.ID, ch1.Artifact.Name, ch1.Target.Type = "ch1-id", "orders", "CLOUDHUB"
+	rtf.ID, rtf.Artifact.Name, rtf.Target.Type, rtf.Target.Subtype = "rtf-id", "orders", "MC", "runtime-fabric"
+	other.ID, other.Artifact.Name, other.Target.Type = "other-id", "billing", "CLOUDHUB"
+
+	matches := anypoint.FilterApps([]anypoint.App{ch1, other, rtf}, anypoint.FilterByName("orders"))
+	_, err := resolveSingleApp(matches, "orders")
+	if err == nil {
+		t.Fatal("resolveSingleApp succeeded with an app deployed to two targets")
+	}
+	for _, want := range []string{"2 apps are named orders", "CLOUDHUB", "runtime-fabric", "--app-type"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q lacks %q", err, want)
+		}
+	}
+
+	// --app-type rtf picks one of them.
+	app, err := resolveSingleApp(anypoint.FilterApps(matches, anypoint.FilterRTF), "orders")
+	if err != nil {
+		t.Fatalf("resolveSingleApp with --app-type rtf: %v", err)
+	}
+	if app.ID != "rtf-id" {
+		t.Errorf("resolved %s, want rtf-id", app.ID)
+	}
+}
@@ -0,0 +1,114 @@
+// Package notify sends the apps found idle by the monitor command to chat webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IdleApp is an app that received no requests over the monitored window.
+type IdleApp struct {
+	ID         string
+	Type       string
+	LastCalled time.Time // zero when the app has no monitoring data
+}
+
+// WebhookNotifier posts a message listing idle apps to a chat webhook.
+type WebhookNotifier interface {
+	// Notify posts title followed by the apps. It does nothing when apps is empty.
+	Notify(ctx context.Context, title string, apps []IdleApp) error
+}
+
+const (
+	// slackAppsPerBlock keeps each section under the 3000 characters Slack accepts.
+	slackAppsPerBlock = 20
+	// slackMaxBlocks is the maximum number of blocks of a Slack message.
+	slackMaxBlocks = 50
+)
+
+// SlackNotifier posts to a Slack incoming webhook.
+type SlackNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewSlackNotifier returns a notifier posting to the given Slack incoming webhook URL.
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{URL: url, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackMessageFor builds a message with a header and one section per slackAppsPerBlock apps.
+// The apps that do not fit in a single message are counted in a trailing context block.
+func slackMessageFor(title string, apps []IdleApp) slackMessage {
+	msg := slackMessage{
+		Text:   title,
+		Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: title}}},
+	}
+	for start := 0; start < len(apps); start += slackAppsPerBlock {
+		// Keep a block for the overflow note.
+		if len(msg.Blocks) == slackMaxBlocks-1 {
+			note := fmt.Sprintf("…and %d more apps.", len(apps)-start)
+			msg.Blocks = append(msg.Blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: note}}})
+			break
+		}
+		end := min(start+slackAppsPerBlock, len(apps))
+		var lines []string
+		for _, app := range apps[start:end] {
+			lastCalled := "No data"
+			if !app.LastCalled.IsZero() {
+				lastCalled = app.LastCalled.Format(time.RFC1123)
+			}
+			lines = append(lines, fmt.Sprintf("• `%s` (%s), last called: %s", app.ID, app.Type, lastCalled))
+		}
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")}})
+	}
+	return msg
+}
+
+// Notify posts the apps to the webhook. Non-2xx responses are returned as errors.
+func (n *SlackNotifier) Notify(ctx context.Context, title string, apps []IdleApp) error {
+	if len(apps) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(slackMessageFor(title, apps))
+	if err != nil {
+		return fmt.Errorf("error encoding Slack message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error posting to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("slack webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
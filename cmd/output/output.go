@@ -0,0 +1,102 @@
+// Package output renders the results of the commands in the formats selected with the
+// global --output flag.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The formats every OutputRenderer supports.
+const (
+	Table = "table"
+	JSON  = "json"
+	CSV   = "csv"
+)
+
+// The formats only the monitor command supports.
+const (
+	InfluxLine = "influx-line"
+	Timeseries = "timeseries"
+	Markdown   = "markdown"
+)
+
+// Validate returns an error unless format is one of the allowed formats.
+func Validate(format string, allowed ...string) error {
+	for _, f := range allowed {
+		if format == f {
+			return nil
+		}
+	}
+	quoted := make([]string, len(allowed))
+	for i, f := range allowed {
+		quoted[i] = "'" + f + "'"
+	}
+	valid := quoted[len(quoted)-1]
+	if len(quoted) > 1 {
+		valid = strings.Join(quoted[:len(quoted)-1], ", ") + " or " + valid
+	}
+	return fmt.Errorf("invalid output format %q: valid values are %s", format, valid)
+}
+
+// OutputRenderer is implemented by the results of the commands to render them in the
+// shared formats.
+type OutputRenderer interface {
+	RenderTable(w io.Writer) error
+	RenderJSON(w io.Writer) error
+	RenderCSV(w io.Writer) error
+}
+
+// Render writes r to w in the given format.
+func Render(w io.Writer, format string, r OutputRenderer) error {
+	switch format {
+	case Table:
+		return r.RenderTable(w)
+	case JSON:
+		return r.RenderJSON(w)
+	case CSV:
+		return r.RenderCSV(w)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// WriteJSON writes v to w as indented JSON.
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// markdownEscaper escapes the characters that would break a Markdown table cell.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+// WriteMarkdown writes a GitHub-flavored Markdown table with the given header and rows.
+func WriteMarkdown(w io.Writer, header []string, rows [][]string) error {
+	writeRow := func(cells []string) error {
+		escaped := make([]string, len(cells))
+		for i, c := range cells {
+			escaped[i] = markdownEscaper.Replace(c)
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+		return err
+	}
+	if err := writeRow(header); err != nil {
+		return err
+	}
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	if _, err := fmt.Fprintf(w, "|%s|\n", strings.Join(separator, "|")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writeRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
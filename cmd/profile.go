@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage configuration profiles",
+	Long: `Manage configuration profiles, so that several connected apps (e.g. one per org) can be
+used without running 'connect' again. Use --profile on any command to use a profile for a single run.`,
+}
+
+// profileListCmd represents the profile list command
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configuration profiles",
+	Long:  `List the configuration profiles. The active profile is marked with an asterisk.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		active := config.ActiveProfile()
+		for _, name := range config.ListProfiles() {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+	},
+}
+
+// profileUseCmd represents the profile use command
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch the active configuration profile",
+	Long: `Switch the active configuration profile. If the profile does not exist yet,
+run 'connect' afterwards to store its credentials.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		exists := false
+		for _, p := range config.ListProfiles() {
+			if p == name {
+				exists = true
+				break
+			}
+		}
+		if err := config.UseProfile(name); err != nil {
+			fmt.Printf("Error switching profile: %v\n", err)
+			return
+		}
+		fmt.Printf("Active profile: %s\n", name)
+		if !exists {
+			fmt.Println("This profile is new. Run 'connect' to store its credentials.")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+// queryEpochs are the accepted values of the --epoch flag.
+var queryEpochs = []string{"ns", "u", "ms", "s", "m", "h", "rfc3339"}
+
+// queryCmd groups the commands running raw queries.
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Run raw monitoring queries",
+	Long:  `Run raw queries against the Anypoint Monitoring datasources.`,
+}
+
+// queryInfluxCmd represents the query influx command
+var queryInfluxCmd = &cobra.Command{
+	Use:   "influx",
+	Short: "Run a raw InfluxQL query",
+	Long: `Run a raw InfluxQL query against the Anypoint Monitoring InfluxDB datasource of the
+connected organization and print the returned series, e.g. to query custom measurements.
+
+The query runs against the database of the datasource, or the one given with --influx-db.
+Timestamps are returned as epoch milliseconds by default; use --epoch to pick another
+precision, or rfc3339 for RFC3339 timestamps.
+
+Example:
+  muletracker-cli query influx --query 'SHOW MEASUREMENTS'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		query, _ := cmd.Flags().GetString("query")
+		epoch, _ := cmd.Flags().GetString("epoch")
+		format, _ := cmd.Flags().GetString("output")
+
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if !slices.Contains(queryEpochs, epoch) {
+			fmt.Printf("Invalid epoch. Valid values are %s.\n", strings.Join(queryEpochs, ", "))
+			return
+		}
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+
+		resp, err := client.Query(ctx, query, epoch)
+		if err != nil {
+			fmt.Printf("Error running query: %v\n", err)
+			os.Exit(1)
+		}
+
+		if format == output.JSON {
+			printJSON(os.Stdout, resp)
+			return
+		}
+		printInfluxSeries(resp)
+	},
+}
+
+// printInfluxSeries prints every series of the response as a table, preceded by its name and tags.
+func printInfluxSeries(resp *anypoint.InfluxDBResponse) {
+	empty := true
+	for _, result := range resp.Results {
+		for _, series := range result.Series {
+			if !empty {
+				fmt.Println()
+			}
+			empty = false
+			if series.Tags != nil {
+				fmt.Printf("%s %v\n", series.Name, series.Tags)
+			} else {
+				fmt.Println(series.Name)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, strings.Join(series.Columns, "\t"))
+			for _, row := range series.Values {
+				cells := make([]string, len(row))
+				for i, v := range row {
+					cells[i] = formatInfluxValue(v)
+				}
+				fmt.Fprintln(w, strings.Join(cells, "\t"))
+			}
+			w.Flush()
+		}
+	}
+	if empty {
+		fmt.Println("No series returned.")
+	}
+}
+
+// formatInfluxValue formats a value of an InfluxDB series. Numbers are printed in full,
+// so that epoch timestamps are not shown in scientific notation.
+func formatInfluxValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.AddCommand(queryInfluxCmd)
+	queryInfluxCmd.Flags().StringP("query", "q", "", "InfluxQL query to run")
+	queryInfluxCmd.MarkFlagRequired("query")
+	queryInfluxCmd.Flags().String("epoch", "ms", "Precision of the returned timestamps: ns, u, ms (default), s, m, h or rfc3339")
+}
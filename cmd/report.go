@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+// defaultEOLWithin is how close an end of support must be to be flagged, by default.
+const defaultEOLWithin = 90 * 24 * time.Hour
+
+// The support statuses of a Mule runtime.
+const (
+	supportEnded   = "END OF SUPPORT"
+	supportEnding  = "ENDING SOON"
+	supportOK      = "OK"
+	supportUnknown = "UNKNOWN"
+)
+
+// RuntimeResult describes the Mule runtime of an app and its support status.
+type RuntimeResult struct {
+	AppID        string     `json:"appId"`
+	AppType      string     `json:"appType"`
+	MuleVersion  string     `json:"muleVersion"`
+	EndOfSupport *time.Time `json:"endOfSupport,omitempty"`
+	Status       string     `json:"status"`
+}
+
+// parseDays parses a duration given in days, e.g. "90d", or as a Go duration, e.g. "36h".
+func parseDays(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q, expected days such as 90d or a duration such as 36h", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected days such as 90d or a duration such as 36h", value)
+	}
+	return d, nil
+}
+
+// runtimeResult returns the runtime of app, flagged when its end of support is past or
+// within the given duration of now.
+func runtimeResult(app anypoint.App, within time.Duration, now time.Time) RuntimeResult {
+	res := RuntimeResult{AppID: app.Artifact.Name, AppType: app.GetType(), MuleVersion: app.MuleVersion.Version, Status: supportUnknown}
+	if app.MuleVersion.EndOfSupportDate > 0 {
+		eos := time.UnixMilli(app.MuleVersion.EndOfSupportDate)
+		res.EndOfSupport = &eos
+		switch {
+		case !eos.After(now):
+			res.Status = supportEnded
+		case eos.Sub(now) <= within:
+			res.Status = supportEnding
+		default:
+			res.Status = supportOK
+		}
+	}
+	return res
+}
+
+// sortRuntimes sorts the runtimes by end of support, soonest first. Runtimes without an
+// end of support date are listed last.
+func sortRuntimes(runtimes []RuntimeResult) {
+	sort.SliceStable(runtimes, func(i, j int) bool {
+		a, b := runtimes[i].EndOfSupport, runtimes[j].EndOfSupport
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.Before(*b)
+	})
+}
+
+// colorSupportStatus renders a support status for the terminal, in red when the support
+// ended and in yellow when it ends soon.
+func colorSupportStatus(status string) string {
+	switch status {
+	case supportEnded:
+		return newColor(os.Stdout, color.FgRed, color.Bold).Sprint(status)
+	case supportEnding:
+		return newColor(os.Stdout, color.FgYellow).Sprint(status)
+	}
+	return status
+}
+
+// printRuntimesTable prints the runtimes using tabwriter for alignment. The status comes
+// last so that its colors don't break the alignment.
+func printRuntimesTable(runtimes []RuntimeResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "App ID\tType\tMule Version\tEnd of Support\tStatus")
+	fmt.Fprintln(w, "------\t----\t------------\t--------------\t------")
+	for _, r := range runtimes {
+		eos := "-"
+		if r.EndOfSupport != nil {
+			eos = r.EndOfSupport.Format(time.DateOnly)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.AppID, r.AppType, r.MuleVersion, eos, colorSupportStatus(r.Status))
+	}
+	w.Flush()
+}
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Audit the apps of an environment",
+	Long:  `Audit the apps deployed in an environment, e.g. to plan upgrades.`,
+}
+
+// reportRuntimesCmd represents the report runtimes command
+var reportRuntimesCmd = &cobra.Command{
+	Use:   "runtimes",
+	Short: "List the Mule runtime of each app and its end of support",
+	Long: `List the Mule runtime version of each app of an environment with its end of support date,
+soonest first. Runtimes whose support has ended are flagged in red, and those whose support
+ends within --eol-within (90 days by default) in yellow.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		orgID, _ := cmd.Flags().GetString("org")
+		envID, _ := cmd.Flags().GetString("env")
+		eolWithin, _ := cmd.Flags().GetString("eol-within")
+		format, _ := cmd.Flags().GetString("output")
+
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON); err != nil {
+			fmt.Println(err)
+			return
+		}
+		within, err := parseDays(eolWithin)
+		if err != nil {
+			fmt.Printf("--eol-within: %v\n", err)
+			return
+		}
+
+		// Retrieve the authenticated client.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+		if (client.IsOrgEmpty() && orgID == "") || (client.IsEnvEmpty() && envID == "") {
+			fmt.Println("Please provide --org, --env flags")
+			return
+		}
+		orgID, envID, err = resolveOrgEnv(ctx, client, orgID, envID)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if orgID == "" {
+			orgID = client.Org
+		}
+		if envID == "" {
+			envID = client.Env
+		}
+
+		apps, err := client.GetApps(ctx, orgID, envID)
+		if err != nil {
+			fmt.Printf("Error retrieving apps: %v\n", err)
+			return
+		}
+		now := time.Now()
+		runtimes := make([]RuntimeResult, 0, len(apps))
+		for _, app := range apps {
+			runtimes = append(runtimes, runtimeResult(app, within, now))
+		}
+		sortRuntimes(runtimes)
+
+		if format == output.JSON {
+			printJSON(os.Stdout, runtimes)
+			return
+		}
+		if len(runtimes) == 0 {
+			fmt.Println("No apps found for the given org and env.")
+			return
+		}
+		printRuntimesTable(runtimes)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportRuntimesCmd)
+	reportRuntimesCmd.Flags().String("org", "", "Organization ID or name (defaults to the persisted one)")
+	reportRuntimesCmd.Flags().String("env", "", "Environment ID or name (defaults to the persisted one)")
+	reportRuntimesCmd.Flags().String("eol-within", "90d", "Flag the runtimes whose support ends within this duration (e.g., 30d, 180d)")
+	reportRuntimesCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
+	reportRuntimesCmd.RegisterFlagCompletionFunc("env", completePersisted("env"))
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+)
+
+// guidPattern matches the IDs of the Anypoint Platform business groups and environments.
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// resolveOrgEnv returns the IDs of the org and env given by ID or by name. An org name is
+// looked up in the business group tree of the persisted org, an env name in the
+// environments of the org (the persisted one when orgArg is empty). Names are compared
+// case-insensitively; empty arguments are returned as is.
+func resolveOrgEnv(ctx context.Context, client *anypoint.Client, orgArg, envArg string) (orgID, envID string, err error) {
+	orgID = orgArg
+	if orgArg != "" && !guidPattern.MatchString(orgArg) {
+		if orgID, err = resolveOrgName(ctx, client, orgArg); err != nil {
+			return "", "", err
+		}
+	}
+
+	envID = envArg
+	if envArg != "" && !guidPattern.MatchString(envArg) {
+		envOrg := orgID
+		if envOrg == "" {
+			envOrg = client.Org
+		}
+		if envOrg == "" {
+			return "", "", fmt.Errorf("cannot resolve environment %s: no business group given", envArg)
+		}
+		environments, err := client.GetEnvironments(ctx, envOrg)
+		if err != nil {
+			return "", "", fmt.Errorf("error retrieving environments: %w", err)
+		}
+		env, err := findEnvironment(environments, "", envArg)
+		if err != nil {
+			return "", "", err
+		}
+		envID = env.GetId()
+	}
+	return orgID, envID, nil
+}
+
+// resolveOrgName returns the ID of the business group named name in the tree of the
+// persisted org, starting from its root.
+func resolveOrgName(ctx context.Context, client *anypoint.Client, name string) (string, error) {
+	if client.Org == "" {
+		return "", fmt.Errorf("cannot resolve business group %s by name: no business group persisted, use its ID", name)
+	}
+	detail, err := client.GetBusinessGroup(ctx, client.Org)
+	if err != nil {
+		return "", err
+	}
+	root := client.Org
+	if parents := detail.GetParentOrganizationIds(); len(parents) > 0 {
+		root = parents[0]
+	}
+	nodes, err := client.GetBusinessGroupTree(ctx, root)
+	if err != nil {
+		return "", err
+	}
+	return findBusinessGroupByName(nodes, name)
+}
+
+// findBusinessGroupByName returns the ID of the only business group of nodes named name,
+// compared case-insensitively.
+func findBusinessGroupByName(nodes []anypoint.BusinessGroupNode, name string) (string, error) {
+	var matches []anypoint.BusinessGroupNode
+	for _, node := range nodes {
+		if strings.EqualFold(node.Name, name) {
+			matches = append(matches, node)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no business group named %s", name)
+	case 1:
+		return matches[0].ID, nil
+	}
+	candidates := make([]string, 0, len(matches))
+	for _, node := range matches {
+		candidates = append(candidates, fmt.Sprintf("%s (ID: %s)", node.Path, node.ID))
+	}
+	return "", fmt.Errorf("%d business groups are named %s, use the ID of one of: %s", len(matches), name, strings.Join(candidates, ", "))
+}
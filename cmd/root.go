@@ -1,12 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/fatih/color"
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
 	"github.com/spf13/cobra"
 )
 
+// cfgFile is the configuration file given with --config.
+var cfgFile string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "MuleTracket",
@@ -14,6 +26,29 @@ var rootCmd = &cobra.Command{
 	Long: `MuleTracket is a CLI tool built in Go to monitor MuleSoft applications.
 It allows you to connect to the Anypoint Platform, navigate through Business Groups
 and Environments, and analyze application usage such as last call time and request counts.`,
+	// Select the configuration profile before any command reads it.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			config.SetProfile(profile)
+		}
+		anypoint.HTTPTimeout, _ = cmd.Flags().GetDuration("http-timeout")
+		anypoint.InfluxDatabase, _ = cmd.Flags().GetString("influx-db")
+		logLevel, _ := cmd.Flags().GetString("log-level")
+		level, err := anypoint.ParseLogLevel(logLevel)
+		if err != nil {
+			fmt.Printf("Invalid log level %q. Valid values are 'debug', 'info', 'warn' or 'error'.\n", logLevel)
+			os.Exit(1)
+		}
+		if debug, _ := cmd.Flags().GetBool("debug"); debug {
+			level = slog.LevelDebug
+		}
+		anypoint.SetLogLevel(level)
+		// Keep escape codes out of the machine-readable outputs.
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		if format, _ := cmd.Flags().GetString("output"); noColor || format != output.Table {
+			color.NoColor = true
+		}
+	},
 	// You can add a Run function if you want default behavior:
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Welcome to MuleTracket CLI. Use -h for help on available commands.")
@@ -21,14 +56,41 @@ and Environments, and analyze application usage such as last call time and reque
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// The command context is cancelled on Ctrl-C or SIGTERM, so that long runs stop promptly and
+// report their partial results; a second signal kills the process right away.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// initConfig initializes the configuration using Viper, from the file given with --config if any.
+func initConfig() {
+	if err := config.InitConfig(cfgFile); err != nil {
+		log.Fatalf("Error initializing config: %v", err)
+	}
+}
+
 func init() {
+	// The config package logs through the default logger.
+	slog.SetDefault(anypoint.Logger)
+	cobra.OnInitialize(initConfig)
+
 	// Here you can add persistent flags and configuration settings.
-	rootCmd.PersistentFlags().StringP("config", "f", "", "config file (default is $HOME/.muletracker.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "f", "", "config file (default is $XDG_CONFIG_HOME/muletracker/config.yaml)")
+	rootCmd.PersistentFlags().StringP("profile", "p", "", "configuration profile to use (default is the active profile)")
+	rootCmd.PersistentFlags().Duration("http-timeout", anypoint.HTTPTimeout, "timeout of each call to the Anypoint Platform, retries included")
+	rootCmd.PersistentFlags().String("log-level", "warn", "level of the diagnostics logged to stderr: debug, info, warn or error")
+	rootCmd.PersistentFlags().Bool("debug", false, "shorthand for --log-level debug: log requests, retries and raw API error responses")
+	rootCmd.PersistentFlags().String("output", output.Table, "output format: table, json or csv, plus the formats specific to a command (see its help)")
+	rootCmd.RegisterFlagCompletionFunc("output", completeOutput)
+	rootCmd.PersistentFlags().Bool("no-color", false, "disable colors (also disabled when the output is not a terminal or NO_COLOR is set)")
+	rootCmd.PersistentFlags().String("influx-db", "", "monitoring database to query (default is the one named by the bootdata, or "+anypoint.DefaultInfluxDatabase+")")
 }
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/spf13/cobra"
+)
+
+// promLabelEscaper escapes the characters that are special in Prometheus label values.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsCollector holds the results of the last collection, served on /metrics.
+type metricsCollector struct {
+	mu          sync.RWMutex
+	orgID       string
+	envID       string
+	results     []AppResult
+	collectedAt time.Time
+}
+
+// update replaces the served results with those of a new collection.
+func (m *metricsCollector) update(results []AppResult, at time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = results
+	m.collectedAt = at
+}
+
+// writeMetrics writes the last collected results in the Prometheus text exposition format.
+// Apps that could not be monitored are left out rather than reported as idle.
+func (m *metricsCollector) writeMetrics(w io.Writer) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	labels := func(r AppResult) string {
+		return fmt.Sprintf(`app_id="%s",app_type="%s",org="%s",env="%s"`,
+			promLabelEscaper.Replace(r.AppID), promLabelEscaper.Replace(r.AppType),
+			promLabelEscaper.Replace(m.orgID), promLabelEscaper.Replace(m.envID))
+	}
+
+	fmt.Fprintln(w, "# HELP muletracker_app_request_count Number of requests received by the app over the request count window.")
+	fmt.Fprintln(w, "# TYPE muletracker_app_request_count gauge")
+	for _, r := range m.results {
+		if r.Err == nil {
+			fmt.Fprintf(w, "muletracker_app_request_count{%s} %d\n", labels(r), r.RequestCount)
+		}
+	}
+	fmt.Fprintln(w, "# HELP muletracker_app_last_called_timestamp Unix time, in seconds, of the last request received by the app.")
+	fmt.Fprintln(w, "# TYPE muletracker_app_last_called_timestamp gauge")
+	for _, r := range m.results {
+		// Only emit the last-called sample when data is available.
+		if r.Err == nil && !r.LastCalled.IsZero() {
+			fmt.Fprintf(w, "muletracker_app_last_called_timestamp{%s} %d\n", labels(r), r.LastCalled.Unix())
+		}
+	}
+	if !m.collectedAt.IsZero() {
+		fmt.Fprintln(w, "# HELP muletracker_last_collection_timestamp Unix time, in seconds, of the last completed collection.")
+		fmt.Fprintln(w, "# TYPE muletracker_last_collection_timestamp gauge")
+		fmt.Fprintf(w, "muletracker_last_collection_timestamp %d\n", m.collectedAt.Unix())
+	}
+}
+
+// ServeHTTP serves the metrics.
+func (m *metricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.writeMetrics(w)
+}
+
+// collectMetrics monitors the apps on every interval, until ctx is cancelled. The apps are
+// listed again on each run so that new deployments are picked up.
+func collectMetrics(ctx context.Context, client *anypoint.Client, m *metricsCollector, appType, lcWindow, rcWindow string, concurrency int, rate float64, interval time.Duration) {
+	opts := monitorOptions{orgID: m.orgID, envID: m.envID, lcWindow: lcWindow, rcWindow: rcWindow, concurrency: concurrency, rate: rate}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		apps, err := getAppsToMonitor(ctx, client, m.orgID, m.envID, nil, false, append(appStatusFilters("running"), appTypeFilters(appType)...)...)
+		if err != nil {
+			anypoint.Logger.Warn("unable to retrieve apps", "err", err)
+		} else {
+			results := monitorAppsConcurrently(ctx, client, opts, apps, 0, nil)
+			if ctx.Err() != nil {
+				return
+			}
+			m.update(results, time.Now())
+			anypoint.Logger.Info("metrics collected", "apps", len(results))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose the monitoring results as Prometheus metrics",
+	Long: `Periodically monitor the apps of an environment and expose the results on /metrics in
+the Prometheus text format, so that they can be scraped into Prometheus or Grafana.
+
+The request count and last-called time of each app are exposed as the
+muletracker_app_request_count and muletracker_app_last_called_timestamp gauges, labeled
+with app_id, app_type, org and env. Press Ctrl-C to stop.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := cmd.Context()
+		orgID, _ := cmd.Flags().GetString("org")
+		envID, _ := cmd.Flags().GetString("env")
+		appType, _ := cmd.Flags().GetString("app-type")
+		lcWindow, _ := cmd.Flags().GetString("last-called-window")
+		rcWindow, _ := cmd.Flags().GetString("request-count-window")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		rate, _ := cmd.Flags().GetFloat64("rate")
+		listen, _ := cmd.Flags().GetString("listen")
+		interval, _ := cmd.Flags().GetDuration("scrape-interval")
+
+		if concurrency < 1 {
+			fmt.Println("--concurrency must be at least 1.")
+			return
+		}
+		if rate <= 0 {
+			fmt.Println("--rate must be positive.")
+			return
+		}
+		if interval <= 0 {
+			fmt.Println("--scrape-interval must be positive.")
+			return
+		}
+
+		// Retrieve the previously connected client from context.
+		client, err := anypoint.GetClientFromContext()
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+		if (client.IsOrgEmpty() && orgID == "") || (client.IsEnvEmpty() && envID == "") {
+			fmt.Println("Please provide --org, --env flags")
+			return
+		}
+		orgID, envID, err = resolveOrgEnv(ctx, client, orgID, envID)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if orgID == "" {
+			orgID = client.Org
+		}
+		if envID == "" {
+			envID = client.Env
+		}
+
+		collector := &metricsCollector{orgID: orgID, envID: envID}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", collector)
+		server := &http.Server{Addr: listen, Handler: mux}
+
+		go collectMetrics(ctx, client, collector, appType, lcWindow, rcWindow, concurrency, rate, interval)
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Serving metrics on http://%s/metrics, collected every %s. Press Ctrl-C to stop.\n", listen, interval)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Error serving metrics: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("org", "", "Organization ID or name (defaults to the persisted one)")
+	serveCmd.Flags().String("env", "", "Environment ID or name (defaults to the persisted one)")
+	serveCmd.RegisterFlagCompletionFunc("org", completePersisted("org"))
+	serveCmd.RegisterFlagCompletionFunc("env", completePersisted("env"))
+	serveCmd.Flags().String("app-type", "all", "Filter apps by type: all (default), cloudhub, cloudhub2 or rtf")
+	serveCmd.RegisterFlagCompletionFunc("app-type", completeValues("all", "cloudhub", "cloudhub2", "rtf"))
+	serveCmd.Flags().String("last-called-window", "15m", "Time window for last-called query (e.g., 15m, 1h, 24h)")
+	serveCmd.Flags().String("request-count-window", "24h", "Time window for request count query (e.g., 24h, 3d)")
+	serveCmd.Flags().Int("concurrency", defaultConcurrency, "Maximum number of apps monitored in parallel")
+	serveCmd.Flags().Float64("rate", defaultRate, "Maximum number of monitoring requests per second")
+	serveCmd.Flags().String("listen", ":9464", "Address the metrics are served on")
+	serveCmd.Flags().Duration("scrape-interval", time.Minute, "Time between two collections of the monitoring results")
+}
@@ -1,19 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
 )
 
 // PrintClientInfo prints non-sensitive client information in a colorful format.
 func PrintClientInfo(client *anypoint.Client) {
 	data := map[string]interface{}{
 		"Connected App Client ID": client.ClientId,
-		"Control Plane":           serverindex2cplane(client.ServerIndex),
+		"Control Plane":           anypoint.ControlPlane(client.ServerIndex),
 		"Token Expires At":        client.ExpiresAt.Format(time.RFC1123),
 		"InfluxDB ID":             client.InfluxDbId,
 		"Business Group Id":       client.Org,
@@ -23,30 +29,104 @@ func PrintClientInfo(client *anypoint.Client) {
 	PrintSimpleResults("Client Information:", data)
 }
 
+// ClientInfo describes the active client, for the JSON output of connect and whoami.
+type ClientInfo struct {
+	ControlPlane   string    `json:"controlPlane"`
+	BaseURL        string    `json:"baseUrl,omitempty"`
+	OrgID          string    `json:"orgId"`
+	OrgName        string    `json:"orgName,omitempty"`
+	EnvID          string    `json:"envId"`
+	EnvName        string    `json:"envName,omitempty"`
+	ClientID       string    `json:"clientId"`
+	TokenExpiresAt time.Time `json:"tokenExpiresAt"`
+	TokenExpired   bool      `json:"tokenExpired"`
+}
+
+// newClientInfo returns the information of client. The org and env names are resolved
+// when possible, and left empty otherwise, e.g. when the token is expired.
+func newClientInfo(ctx context.Context, client *anypoint.Client) ClientInfo {
+	info := ClientInfo{
+		ControlPlane:   anypoint.ControlPlane(client.ServerIndex),
+		BaseURL:        client.BaseURL,
+		OrgID:          client.Org,
+		EnvID:          client.Env,
+		ClientID:       client.ClientId,
+		TokenExpiresAt: client.ExpiresAt,
+		TokenExpired:   client.IsTokenExpired(),
+	}
+	if info.OrgID == "" || info.TokenExpired {
+		return info
+	}
+	if bg, err := client.GetBusinessGroup(ctx, info.OrgID); err != nil {
+		anypoint.Logger.Debug("unable to resolve the business group name", "org", info.OrgID, "err", err)
+	} else {
+		info.OrgName = bg.GetName()
+	}
+	if info.EnvID == "" {
+		return info
+	}
+	if environments, err := client.GetEnvironments(ctx, info.OrgID); err != nil {
+		anypoint.Logger.Debug("unable to resolve the environment name", "env", info.EnvID, "err", err)
+	} else if env, err := findEnvironment(environments, info.EnvID, ""); err == nil {
+		info.EnvName = env.GetName()
+	}
+	return info
+}
+
+// printClientInfoAs prints the information of client in the given --output format:
+// as JSON for "json", as PrintClientInfo does otherwise.
+func printClientInfoAs(ctx context.Context, format string, client *anypoint.Client) {
+	if format == output.JSON {
+		printJSON(os.Stdout, newClientInfo(ctx, client))
+		return
+	}
+	PrintClientInfo(client)
+}
+
 // PrintSimpleResults prints a header and key/value pairs in a simple, aligned style.
 func PrintSimpleResults(header string, data map[string]interface{}) {
+	FprintSimpleResults(os.Stdout, header, data)
+}
+
+// newColor returns a color printing to w. Colors are disabled when w is not a terminal,
+// e.g. when the output is piped or written to a file, in addition to the cases handled
+// by fatih/color (NO_COLOR, --no-color, non-terminal stdout).
+func newColor(w io.Writer, attrs ...color.Attribute) *color.Color {
+	c := color.New(attrs...)
+	if f, ok := w.(*os.File); !ok || !(isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())) {
+		c.DisableColor()
+	}
+	return c
+}
+
+// FprintSimpleResults is like PrintSimpleResults but writes to w.
+func FprintSimpleResults(w io.Writer, header string, data map[string]interface{}) {
 	// Define color functions.
-	headerColor := color.New(color.FgGreen, color.Bold).SprintFunc()
-	keyColor := color.New(color.FgYellow).SprintFunc()
-	valueColor := color.New(color.FgWhite).SprintFunc()
+	headerColor := newColor(w, color.FgGreen, color.Bold).SprintFunc()
+	keyColor := newColor(w, color.FgYellow).SprintFunc()
+	valueColor := newColor(w, color.FgWhite).SprintFunc()
 
-	// Determine the maximum key width for alignment.
+	// Determine the maximum key width for alignment, and list the keys in a stable order.
 	maxKeyLength := 0
+	keys := make([]string, 0, len(data))
 	for k := range data {
+		keys = append(keys, k)
 		if len(k) > maxKeyLength {
 			maxKeyLength = len(k)
 		}
 	}
+	sort.Strings(keys)
 
 	// Define a divider line.
 	divider := strings.Repeat("-", maxKeyLength+25)
 
 	// Print the header.
-	fmt.Println(headerColor(header))
-	fmt.Println(divider)
+	fmt.Fprintln(w, headerColor(header))
+	fmt.Fprintln(w, divider)
 
 	// Print each key/value pair.
-	for key, val := range data {
+	for _, key := range keys {
+		val := data[key]
 		// Format time values specially.
 		var formattedVal string
 		switch t := val.(type) {
@@ -61,9 +141,9 @@ func PrintSimpleResults(header string, data map[string]interface{}) {
 		}
 
 		// Left-align the key using the maximum width.
-		fmt.Printf("%s: %s\n", keyColor(fmt.Sprintf("%-*s", maxKeyLength, key)), valueColor(formattedVal))
+		fmt.Fprintf(w, "%s: %s\n", keyColor(fmt.Sprintf("%-*s", maxKeyLength, key)), valueColor(formattedVal))
 	}
 
 	// Print the divider again.
-	fmt.Println(divider)
+	fmt.Fprintln(w, divider)
 }
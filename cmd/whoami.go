@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mulesoft-anypoint/muletracker-cli/anypoint"
+	"github.com/mulesoft-anypoint/muletracker-cli/cmd/output"
+	"github.com/mulesoft-anypoint/muletracker-cli/config"
+	"github.com/spf13/cobra"
+)
+
+// whoamiCmd represents the whoami command
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the active identity and token status",
+	Long: `Show which connected app, control plane, org and environment the persisted session
+points at, and whether its access token is still valid. An expired token is reported, not refreshed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("output")
+		format = strings.ToLower(format)
+		if err := output.Validate(format, output.Table, output.JSON); err != nil {
+			fmt.Println(err)
+			return
+		}
+		client, err := anypoint.GetClientFromContext(anypoint.WithSkipTokenExpiration())
+		if err != nil {
+			fmt.Printf("Error retrieving client: %v\n", err)
+			return
+		}
+		if format == output.JSON {
+			printClientInfoAs(cmd.Context(), format, client)
+			return
+		}
+
+		var status string
+		remaining := time.Until(client.ExpiresAt).Round(time.Second)
+		if client.IsTokenExpired() {
+			status = fmt.Sprintf("Expired %s ago", -remaining)
+		} else {
+			status = fmt.Sprintf("Valid, expires in %s", remaining)
+		}
+
+		PrintSimpleResults("Active Identity:", map[string]interface{}{
+			"Profile":                 config.ActiveProfile(),
+			"Control Plane":           anypoint.ControlPlane(client.ServerIndex),
+			"Connected App Client ID": client.ClientId,
+			"Business Group Id":       client.Org,
+			"Environment Id":          client.Env,
+			"InfluxDB ID":             client.InfluxDbId,
+			"Token Expires At":        client.ExpiresAt.Format(time.RFC1123),
+			"Token Status":            status,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
@@ -1,27 +1,77 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/viper"
 )
 
-const configFileName = ".muletracker" // without extension
+const (
+	configFileName = ".muletracker" // legacy file in the home directory, without extension
+	configDirName  = "muletracker"  // directory under the XDG config home
+	configFile     = "config.yaml"  // file name inside configDirName
+)
+
+// configPath is the configuration file in use, resolved by InitConfig.
+var configPath string
 
-// InitConfig sets up Viper to read in the configuration file.
-func InitConfig() error {
-	// Find home directory.
+// resolveConfigPath returns the path of the configuration file. The lookup order is:
+//  1. $XDG_CONFIG_HOME/muletracker/config.yaml
+//  2. $HOME/.config/muletracker/config.yaml
+//  3. $HOME/.muletracker.yaml, for backward compatibility
+//
+// An existing legacy file is migrated to the XDG location; if it cannot be moved, it is used in place.
+func resolveConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
+		return "", err
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	path := filepath.Join(configHome, configDirName, configFile)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	legacyPath := filepath.Join(home, configFileName+".yaml")
+	if _, err := os.Stat(legacyPath); err != nil {
+		return path, nil
+	}
+	if err := migrateConfig(legacyPath, path); err != nil {
+		slog.Warn("unable to migrate the configuration file", "from", legacyPath, "to", path, "err", err)
+		return legacyPath, nil
+	}
+	return path, nil
+}
+
+// migrateConfig moves the legacy configuration file to its new location.
+func migrateConfig(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0o700); err != nil {
 		return err
 	}
+	return os.Rename(from, to)
+}
 
-	// Tell viper the name of the config file (without extension)
-	viper.SetConfigName(configFileName)
-	// Add the home directory as the first search path.
-	viper.AddConfigPath(home)
+// InitConfig sets up Viper to read in the configuration file. An empty path selects
+// the default location, see resolveConfigPath.
+func InitConfig(path string) error {
+	if path == "" {
+		resolved, err := resolveConfigPath()
+		if err != nil {
+			return err
+		}
+		path = resolved
+	}
+	configPath = path
+
+	// Tell viper which file to use.
+	viper.SetConfigFile(configPath)
 
 	// Optionally, you can set defaults.
 	viper.SetDefault("serverIndex", 0)
@@ -30,30 +80,114 @@ func InitConfig() error {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err != nil {
+		// A corrupt file is set aside so that the recovery commands, e.g. 'config reset'
+		// or 'connect', still run, starting from the defaults.
+		var parseErr viper.ConfigParseError
+		if errors.As(err, &parseErr) {
+			backupPath := configPath + ".bak"
+			slog.Warn("the configuration file is corrupt, continuing with the defaults", "path", configPath, "backup", backupPath, "err", err)
+			if err := os.Rename(configPath, backupPath); err != nil {
+				return fmt.Errorf("could not back up the corrupt config file: %w", err)
+			}
+			// The backup may still hold secrets.
+			os.Chmod(backupPath, configFileMode)
+			err = os.ErrNotExist
+		}
 		// If the error is because the file doesn't exist, you might want to create one.
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		if errors.Is(err, os.ErrNotExist) {
 			// Create a new file with default values.
-			configPath := filepath.Join(home, configFileName+".yaml")
-			if err := viper.WriteConfigAs(configPath); err != nil {
+			if err := SaveConfig(); err != nil {
 				return fmt.Errorf("could not create config file: %w", err)
 			}
 		} else {
 			return fmt.Errorf("error reading config file: %w", err)
 		}
 	}
+	bindEnv()
 
 	return nil
 }
 
-// SaveConfig persists the current configuration to file.
+// Path returns the configuration file in use, or "" before InitConfig.
+func Path() string {
+	return configPath
+}
+
+// sessionKeys are the settings of a profile that hold the session opened by 'connect'.
+var sessionKeys = []string{"expiresAt", "influxdbId", "influxdbDatabase", "org", "env"}
+
+// credentialKeys are the settings of a profile that identify the connected app.
+var credentialKeys = []string{"clientId", "serverIndex", "controlplane", "baseUrl"}
+
+// ResetProfile clears the settings of the active profile, session and credentials, and
+// persists the configuration. With keepCredentials, only the session is cleared. The
+// settings are blanked to their defaults rather than removed, and the secrets are also
+// removed from the OS keyring.
+func ResetProfile(keepCredentials bool) error {
+	keys := sessionKeys
+	secrets := []string{"accessToken"}
+	if !keepCredentials {
+		keys = append(append([]string{}, keys...), credentialKeys...)
+		secrets = append(secrets, "clientSecret")
+	}
+	for _, key := range keys {
+		switch key {
+		case "influxdbId", "serverIndex":
+			viper.Set(Key(key), 0)
+		default:
+			viper.Set(Key(key), "")
+		}
+	}
+	for _, key := range secrets {
+		if err := SetSecret(Key(key), ""); err != nil {
+			return err
+		}
+	}
+	return SaveConfig()
+}
+
+// fileSettings returns the settings to persist: the current ones, except that the values
+// coming from the environment are replaced by those of the configuration file, if any.
+// Viper would otherwise write e.g. MULETRACKER_CLIENT_SECRET to the file in clear text.
+func fileSettings() *viper.Viper {
+	file := viper.New()
+	file.SetConfigFile(configPath)
+	if err := file.ReadInConfig(); err != nil {
+		// Nothing persisted yet, or a file being replaced.
+		file = viper.New()
+	}
+	out := viper.New()
+	for _, key := range viper.AllKeys() {
+		switch {
+		case !FromEnv(key):
+			out.Set(key, viper.Get(key))
+		case file.IsSet(key):
+			out.Set(key, file.Get(key))
+		}
+	}
+	return out
+}
+
+// configFileMode is the permission of the configuration file, which may hold secrets
+// when the OS keyring is unavailable.
+const configFileMode = 0o600
+
+// SaveConfig persists the current configuration to file, readable by its owner only.
+// It also creates the file on the first run, see InitConfig.
 func SaveConfig() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
+	if configPath == "" {
+		path, err := resolveConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = path
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o700); err != nil {
 		return err
 	}
-	configPath := filepath.Join(home, configFileName+".yaml")
-	if err := viper.WriteConfigAs(configPath); err != nil {
+	if err := fileSettings().WriteConfigAs(configPath); err != nil {
 		return err
 	}
-	return nil
+	// The file is created with the umask permissions, often readable by other users.
+	return os.Chmod(configPath, configFileMode)
 }
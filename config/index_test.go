@@ -0,0 +1,206 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// initTestConfig resets viper and initializes the configuration from a file in a
+// temporary directory, returning its path.
+func initTestConfig(t *testing.T) string {
+	t.Helper()
+	viper.Reset()
+	profileOverride = ""
+	boundEnv = make(map[string]string)
+	t.Cleanup(viper.Reset)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := InitConfig(path); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	return path
+}
+
+func TestSaveConfigSkipsEnvironmentValues(t *testing.T) {
+	t.Setenv("MULETRACKER_CLIENT_SECRET", "s3cr3t")
+	t.Setenv("MULETRACKER_CLIENT_ID", "env-client")
+	path := initTestConfig(t)
+	SetProfile("ci")
+	t.Cleanup(func() { profileOverride = "" })
+
+	viper.Set(Key("clientId"), "env-client")
+	viper.Set(Key("org"), "my-org")
+	if err := SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"s3cr3t", "env-client"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("config file contains %q from the environment:\n%s", leaked, data)
+		}
+	}
+	if !strings.Contains(string(data), "my-org") {
+		t.Errorf("config file lacks the persisted org:\n%s", data)
+	}
+}
+
+func TestSaveConfigKeepsFileValueOverriddenByEnvironment(t *testing.T) {
+	path := initTestConfig(t)
+	if err := os.WriteFile(path, []byte("org: file-org\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MULETRACKER_ORG", "env-org")
+	if err := InitConfig(path); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if got := viper.GetString("org"); got != "env-org" {
+		t.Fatalf("org = %q, want the environment value", got)
+	}
+
+	if err := SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "env-org") || !strings.Contains(string(data), "file-org") {
+		t.Errorf("config file should keep the file value:\n%s", data)
+	}
+}
+
+func TestConfigFileMode(t *testing.T) {
+	path := initTestConfig(t)
+	assertMode := func(when string) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != configFileMode {
+			t.Errorf("mode %s = %#o, want %#o", when, perm, configFileMode)
+		}
+	}
+	assertMode("after creation")
+
+	// A file left readable by others, e.g. by an older version, is restricted on save.
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set(Key("org"), "my-org")
+	if err := SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	assertMode("after save")
+}
+
+func TestInitConfigBacksUpCorruptFile(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	corrupt := "clientId: [unterminated\n\tclientSecret: s3cr3t\n"
+	if err := os.WriteFile(path, []byte(corrupt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InitConfig(path); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("corrupt file not backed up: %v", err)
+	}
+	if string(backup) != corrupt {
+		t.Errorf("backup = %q, want the corrupt file", backup)
+	}
+	if info, err := os.Stat(path + ".bak"); err == nil && info.Mode().Perm() != configFileMode {
+		t.Errorf("backup mode = %#o, want %#o", info.Mode().Perm(), configFileMode)
+	}
+
+	// A fresh configuration replaces the corrupt one and loads on the next run.
+	if data, err := os.ReadFile(path); err != nil || string(data) == corrupt {
+		t.Fatalf("config file not recreated: %q, %v", data, err)
+	}
+	viper.Reset()
+	if err := InitConfig(path); err != nil {
+		t.Fatalf("InitConfig of the fresh config: %v", err)
+	}
+	if got := viper.GetString("clientId"); got != "" {
+		t.Errorf("clientId = %q, want the default", got)
+	}
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		t.Errorf("backup removed by the next run: %v", err)
+	}
+}
+
+// setHomes points the home and XDG config directories to temporary ones, returning them.
+func setHomes(t *testing.T) (home, configHome string) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	home, configHome = t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	return home, configHome
+}
+
+func TestConfigUsesXDGConfigHome(t *testing.T) {
+	home, configHome := setHomes(t)
+	want := filepath.Join(configHome, "muletracker", "config.yaml")
+
+	if err := InitConfig(""); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if configPath != want {
+		t.Errorf("config path = %s, want %s", configPath, want)
+	}
+	viper.Set(Key("org"), "my-org")
+	if err := SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if data, err := os.ReadFile(want); err != nil || !strings.Contains(string(data), "my-org") {
+		t.Errorf("configuration not written to %s: %q, %v", want, data, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".muletracker.yaml")); !os.IsNotExist(err) {
+		t.Errorf("legacy file written to the home directory: %v", err)
+	}
+
+	// The next run reads it back.
+	viper.Reset()
+	if err := InitConfig(""); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if got := viper.GetString(Key("org")); got != "my-org" {
+		t.Errorf("org = %q, want my-org", got)
+	}
+}
+
+func TestConfigMigratesLegacyFile(t *testing.T) {
+	home, configHome := setHomes(t)
+	legacy := filepath.Join(home, ".muletracker.yaml")
+	if err := os.WriteFile(legacy, []byte("org: legacy-org\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(configHome, "muletracker", "config.yaml")
+
+	if err := InitConfig(""); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+	if configPath != want {
+		t.Errorf("config path = %s, want %s", configPath, want)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy file not moved: %v", err)
+	}
+	if got := viper.GetString(Key("org")); got != "legacy-org" {
+		t.Errorf("org = %q, want the legacy value", got)
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultProfile is the profile stored in the flat, top-level layout of the configuration.
+	DefaultProfile = "default"
+	// activeProfileKey is the configuration key holding the profile selected with 'profile use'.
+	activeProfileKey = "activeProfile"
+	// profilesKey is the configuration key under which the named profiles are stored.
+	profilesKey = "profiles"
+)
+
+// profileOverride is the profile selected for this run with --profile, if any.
+var profileOverride string
+
+// SetProfile selects the profile to use for this run, overriding the persisted active profile.
+func SetProfile(name string) {
+	profileOverride = name
+	bindEnv()
+}
+
+// ActiveProfile returns the profile in use: the one given with --profile, otherwise the
+// one persisted with UseProfile, otherwise the default profile.
+func ActiveProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if name := viper.GetString(activeProfileKey); name != "" {
+		return name
+	}
+	return DefaultProfile
+}
+
+// UseProfile persists name as the active profile.
+func UseProfile(name string) error {
+	viper.Set(activeProfileKey, name)
+	return SaveConfig()
+}
+
+// Key returns the configuration key of a setting in the active profile. The default
+// profile keeps the flat layout for backward compatibility; the other profiles are
+// stored under profiles.<name>.
+func Key(key string) string {
+	return ProfileKey(ActiveProfile(), key)
+}
+
+// ProfileKey returns the configuration key of a setting in the given profile.
+func ProfileKey(profile, key string) string {
+	if profile == DefaultProfile {
+		return key
+	}
+	return profilesKey + "." + profile + "." + key
+}
+
+// ListProfiles returns the names of the configured profiles, the default profile first.
+// The active profile is listed even if nothing has been stored in it yet.
+func ListProfiles() []string {
+	seen := map[string]bool{DefaultProfile: true}
+	var names []string
+	for name := range viper.GetStringMap(profilesKey) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if active := ActiveProfile(); !seen[active] {
+		names = append(names, active)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// envPrefix is the prefix of the environment variables overriding the configuration.
+const envPrefix = "MULETRACKER"
+
+// envBindings maps the settings of the active profile to the environment variables
+// overriding them, so that CI jobs can run without a configuration file.
+var envBindings = map[string]string{
+	"clientId":     "MULETRACKER_CLIENT_ID",
+	"clientSecret": "MULETRACKER_CLIENT_SECRET",
+	"controlplane": "MULETRACKER_CONTROLPLANE",
+	"baseUrl":      "MULETRACKER_BASE_URL",
+	"org":          "MULETRACKER_ORG",
+	"env":          "MULETRACKER_ENV",
+}
+
+// boundEnv maps the configuration keys bound so far, lower-cased as viper stores them, to
+// their environment variable. Keys of a profile stay bound after switching profiles.
+var boundEnv = make(map[string]string)
+
+// bindEnv binds the settings of the active profile to their environment variables.
+// Settings without an explicit binding are still looked up as MULETRACKER_<KEY>.
+func bindEnv() {
+	viper.SetEnvPrefix(envPrefix)
+	viper.AutomaticEnv()
+	for key, env := range envBindings {
+		viper.BindEnv(Key(key), env)
+		boundEnv[strings.ToLower(Key(key))] = env
+	}
+}
+
+// FromEnv reports whether the value of the configuration key comes from the environment,
+// through its bound variable or AutomaticEnv, rather than from the configuration file.
+// Such values, e.g. credentials given to a CI job, must not be persisted.
+func FromEnv(key string) bool {
+	key = strings.ToLower(key)
+	env, ok := boundEnv[key]
+	if !ok {
+		env = envPrefix + "_" + strings.ToUpper(key)
+	}
+	value, ok := os.LookupEnv(env)
+	return ok && value != "" && value == viper.GetString(key)
+}
+
+// envValue returns the value of the environment variable bound to the configuration
+// key, if it is set.
+func envValue(key string) (string, bool) {
+	for setting, env := range envBindings {
+		if Key(setting) == key {
+			return os.LookupEnv(env)
+		}
+	}
+	return "", false
+}
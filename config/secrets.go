@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name the secrets are stored under in the OS keyring.
+const keyringService = "muletracker"
+
+// SecretStore stores the sensitive configuration values (client secret, access token).
+type SecretStore interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+}
+
+// keyringStore stores secrets in the OS keyring (macOS Keychain, Windows Credential
+// Manager, Secret Service on Linux).
+type keyringStore struct{}
+
+func (keyringStore) Get(key string) (string, error) {
+	return keyring.Get(keyringService, key)
+}
+
+func (keyringStore) Set(key, value string) error {
+	if value == "" {
+		err := keyring.Delete(keyringService, key)
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return keyring.Set(keyringService, key, value)
+}
+
+// fileStore stores secrets in clear text in the configuration file.
+type fileStore struct{}
+
+func (fileStore) Get(key string) (string, error) {
+	return viper.GetString(key), nil
+}
+
+func (fileStore) Set(key, value string) error {
+	viper.Set(key, value)
+	return nil
+}
+
+// secretStore is the store in use. Tests and alternative backends may replace it.
+var secretStore SecretStore = keyringStore{}
+
+var keyringWarning sync.Once
+
+// GetSecret returns the secret stored under key. An environment variable bound to the
+// key takes precedence over the keyring; secrets missing from the keyring are read from
+// the configuration file, where previous versions persisted them.
+func GetSecret(key string) string {
+	if value, ok := envValue(key); ok && value != "" {
+		return value
+	}
+	if value, err := secretStore.Get(key); err == nil && value != "" {
+		return value
+	}
+	value, _ := fileStore{}.Get(key)
+	return value
+}
+
+// SetSecret stores the secret under key. When the keyring is not available, the secret
+// falls back to the configuration file in clear text, with a warning.
+// The caller is responsible for persisting the configuration with SaveConfig.
+func SetSecret(key, value string) error {
+	if err := secretStore.Set(key, value); err != nil {
+		keyringWarning.Do(func() {
+			slog.Warn("OS keyring not available, storing secrets in the configuration file", "err", err)
+		})
+		return fileStore{}.Set(key, value)
+	}
+	// Don't leave a stale clear-text copy in the configuration file.
+	if viper.IsSet(key) {
+		viper.Set(key, "")
+	}
+	return nil
+}